@@ -1,11 +1,7 @@
 package p2pb2b
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
+	"context"
 )
 
 type AccountBalancesResp struct {
@@ -14,8 +10,8 @@ type AccountBalancesResp struct {
 }
 
 type AccountBalance struct {
-	Available float64 `json:"available,string"`
-	Freeze    float64 `json:"freeze,string"`
+	Available Decimal `json:"available"`
+	Freeze    Decimal `json:"freeze"`
 }
 
 type AccountBalancesRequest struct {
@@ -28,8 +24,8 @@ type AccountCurrencyBalanceResp struct {
 }
 
 type AccountCurrencyBalance struct {
-	Available float64 `json:"available,string"`
-	Freeze    float64 `json:"freeze,string"`
+	Available Decimal `json:"available"`
+	Freeze    Decimal `json:"freeze"`
 }
 
 type AccountCurrencyBalanceRequest struct {
@@ -38,56 +34,24 @@ type AccountCurrencyBalanceRequest struct {
 }
 
 func (c *client) PostBalances(request *AccountBalancesRequest) (*AccountBalancesResp, error) {
-	url := fmt.Sprintf("%s/account/balances", c.url)
-	asJSON, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.sendPost(url, nil, bytes.NewReader(asJSON))
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return c.PostBalancesCtx(context.Background(), request)
+}
 
+func (c *client) PostBalancesCtx(ctx context.Context, request *AccountBalancesRequest) (*AccountBalancesResp, error) {
 	var result AccountBalancesResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, balancesEndpoint, "", request, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
 func (c *client) PostCurrencyBalance(request *AccountCurrencyBalanceRequest) (*AccountCurrencyBalanceResp, error) {
-	url := fmt.Sprintf("%s/account/balance", c.url)
-	asJSON, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.sendPost(url, nil, bytes.NewReader(asJSON))
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return c.PostCurrencyBalanceCtx(context.Background(), request)
+}
 
+func (c *client) PostCurrencyBalanceCtx(ctx context.Context, request *AccountCurrencyBalanceRequest) (*AccountCurrencyBalanceResp, error) {
 	var result AccountCurrencyBalanceResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, currencyBalanceEndpoint, "", request, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -0,0 +1,75 @@
+package p2pb2b
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+const (
+	// DefaultMaxResponseSize is the default maximum size of a response body in bytes
+	DefaultMaxResponseSize int64 = 16 << 20
+	// maxErrorBodySize is the maximum size of the body kept in an APIError
+	maxErrorBodySize = 64 << 10
+)
+
+// ErrResponseTooLarge is returned when a response body exceeds the maximum response size
+var ErrResponseTooLarge = errors.New("response too large")
+
+// limitedBody returns a reader of body failing with ErrResponseTooLarge after limit bytes.
+// A limit <= 0 means no limit.
+func limitedBody(body io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		return body
+	}
+	return &limitedReader{r: body, remaining: limit, limit: limit}
+}
+
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	limit     int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// the limit is reached, any further byte exceeds it
+		var probe [1]byte
+		n, err := l.r.Read(probe[:])
+		if n > 0 {
+			return 0, fmt.Errorf("response exceeds %d bytes, %w", l.limit, ErrResponseTooLarge)
+		}
+		return 0, err
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// readLimited reads all of body, failing with ErrResponseTooLarge after limit bytes
+func readLimited(body io.Reader, limit int64) ([]byte, error) {
+	return ioutil.ReadAll(limitedBody(body, limit))
+}
+
+// captureBuffer keeps the first bytes written to it, used to report the body of
+// failed responses which are decoded as a stream
+type captureBuffer struct {
+	buf       []byte
+	limit     int
+	truncated bool
+}
+
+func (c *captureBuffer) Write(p []byte) (int, error) {
+	free := c.limit - len(c.buf)
+	if len(p) > free {
+		c.buf = append(c.buf, p[:free]...)
+		c.truncated = true
+	} else {
+		c.buf = append(c.buf, p...)
+	}
+	return len(p), nil
+}
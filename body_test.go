@@ -0,0 +1,88 @@
+package p2pb2b
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitedBody(t *testing.T) {
+	content, err := readLimited(strings.NewReader("0123456789"), 10)
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789", string(content))
+
+	_, err = readLimited(strings.NewReader("0123456789a"), 10)
+	assert.True(t, errors.Is(err, ErrResponseTooLarge))
+
+	content, err = readLimited(strings.NewReader("0123456789a"), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789a", string(content))
+}
+
+func TestCaptureBuffer(t *testing.T) {
+	capture := &captureBuffer{limit: 5}
+	capture.Write([]byte("abc"))
+	assert.False(t, capture.truncated)
+	n, err := capture.Write([]byte("defg"))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, n)
+	assert.True(t, capture.truncated)
+	assert.Equal(t, "abcde", string(capture.buf))
+}
+
+func TestMaxResponseSize(t *testing.T) {
+	body := `{"success":true,"message":"","result":["` + strings.Repeat("ETH", 100) + `"]}`
+	stub := &stubTransport{status: http.StatusOK, body: body}
+
+	client, err := newClientWithURL("http://p2pb2b.test", "key", "secret", WithTransport(stub), WithMaxResponseSize(100))
+	assert.Nil(t, err)
+	_, err = client.GetSymbols()
+	assert.True(t, errors.Is(err, ErrResponseTooLarge))
+	_, err = client.QueryExecuted(&QueryExecutedRequest{})
+	assert.True(t, errors.Is(err, ErrResponseTooLarge))
+
+	// the limit also applies to responses stored in the cache
+	client, err = newClientWithURL("http://p2pb2b.test", "key", "secret", WithTransport(stub), WithMaxResponseSize(100),
+		WithResponseCache(NewResponseCache(CacheConfig{DefaultTTL: time.Minute})))
+	assert.Nil(t, err)
+	_, err = client.GetSymbols()
+	assert.True(t, errors.Is(err, ErrResponseTooLarge))
+
+	client, err = newClientWithURL("http://p2pb2b.test", "key", "secret", WithTransport(stub), WithMaxResponseSize(int64(len(body))))
+	assert.Nil(t, err)
+	resp, err := client.GetSymbols()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resp.Result))
+
+	_, err = NewClient("key", "secret", WithMaxResponseSize(-1))
+	assert.NotNil(t, err)
+}
+
+func TestDecodeFailedResponseWithDifferentSchema(t *testing.T) {
+	body := `{"success":false,"message":[["Key not provided."]],"result":[]}`
+	stub := &stubTransport{status: http.StatusOK, body: body}
+	client, err := newClientWithURL("http://p2pb2b.test", "key", "secret", WithTransport(stub))
+	assert.Nil(t, err)
+
+	_, err = client.QueryUnexecuted(&QueryUnexecutedRequest{Market: "ETH_BTC", Limit: 10})
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "Key not provided.", apiErr.Message)
+	assert.Equal(t, body, string(apiErr.Body))
+	assert.True(t, IsAuthError(err))
+}
+
+func TestDecodeInvalidJSON(t *testing.T) {
+	stub := &stubTransport{status: http.StatusOK, body: `<html>maintenance</html>`}
+	client, err := newClientWithURL("http://p2pb2b.test", "key", "secret", WithTransport(stub))
+	assert.Nil(t, err)
+
+	_, err = client.GetMarkets()
+	assert.NotNil(t, err)
+	var apiErr *APIError
+	assert.False(t, errors.As(err, &apiErr))
+}
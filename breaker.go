@@ -0,0 +1,247 @@
+package p2pb2b
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen classifies errors of requests rejected by an open circuit breaker
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitOpenError is returned without calling the exchange while a circuit breaker is
+// open, or half-open with all trial requests in flight. It matches ErrCircuitOpen with errors.Is.
+type CircuitOpenError struct {
+	// Breaker is the name of the breaker, public or trading
+	Breaker string
+	// RetryAt is when the breaker lets trial requests through again, zero while half-open
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.RetryAt.IsZero() {
+		return fmt.Sprintf("%s circuit breaker is half-open", e.Breaker)
+	}
+	return fmt.Sprintf("%s circuit breaker is open until %s", e.Breaker, e.RetryAt.Format(time.RFC3339))
+}
+
+// Unwrap returns ErrCircuitOpen
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// CircuitState is the state of a circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all requests
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of trial requests through
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// CircuitBreakerSettings configures a circuit breaker
+type CircuitBreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures opening the breaker, defaults to 5
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before it turns half-open, defaults to 30 seconds
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of concurrent trial requests while half-open and the number
+	// of successes closing the breaker again, defaults to 1
+	HalfOpenRequests int
+}
+
+// CircuitBreakerConfig configures the circuit breakers of the client. Transport errors,
+// HTTP 5xx and 429 responses count as failures; other API errors, e.g. insufficient funds,
+// are answers of a healthy exchange and count as successes.
+type CircuitBreakerConfig struct {
+	// Public guards the public market data endpoints
+	Public CircuitBreakerSettings
+	// Trading guards the signed trading and account endpoints
+	Trading CircuitBreakerSettings
+	// OnStateChange is called with the name of the breaker, public or trading, on every
+	// state change. It is called synchronously by the request causing the change, after the
+	// breaker is unlocked, so it may use the client. It may be called concurrently.
+	OnStateChange func(breaker string, from CircuitState, to CircuitState)
+}
+
+type circuitBreakers [2]*circuitBreaker
+
+func newCircuitBreakers(config CircuitBreakerConfig) (*circuitBreakers, error) {
+	var breakers circuitBreakers
+	for class, settings := range map[endpointClass]CircuitBreakerSettings{classPublic: config.Public, classPrivate: config.Trading} {
+		if settings.FailureThreshold < 0 || settings.OpenTimeout < 0 || settings.HalfOpenRequests < 0 {
+			return nil, fmt.Errorf("circuit breaker settings must not be < 0")
+		}
+		b := &circuitBreaker{
+			name:             "public",
+			failureThreshold: settings.FailureThreshold,
+			openTimeout:      settings.OpenTimeout,
+			halfOpenRequests: settings.HalfOpenRequests,
+			onStateChange:    config.OnStateChange,
+			now:              time.Now,
+		}
+		if class == classPrivate {
+			b.name = "trading"
+		}
+		if b.failureThreshold == 0 {
+			b.failureThreshold = 5
+		}
+		if b.openTimeout == 0 {
+			b.openTimeout = 30 * time.Second
+		}
+		if b.halfOpenRequests == 0 {
+			b.halfOpenRequests = 1
+		}
+		breakers[class] = b
+	}
+	return &breakers, nil
+}
+
+// allow returns the generation of the breaker of the class if a request may be sent
+func (b *circuitBreakers) allow(class endpointClass) (uint64, error) {
+	if b == nil {
+		return 0, nil
+	}
+	return b[class].allow()
+}
+
+// release frees the trial slot of a request which was not sent
+func (b *circuitBreakers) release(class endpointClass, generation uint64) {
+	if b == nil {
+		return
+	}
+	b[class].release(generation)
+}
+
+// done reports the outcome of a request allowed in generation
+func (b *circuitBreakers) done(class endpointClass, generation uint64, request *http.Request, resp *http.Response, err error) {
+	if b == nil {
+		return
+	}
+	if err != nil && request.Context().Err() != nil {
+		// canceled by the caller, this says nothing about the exchange
+		b[class].release(generation)
+		return
+	}
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+	b[class].done(generation, !failed)
+}
+
+type circuitBreaker struct {
+	name             string
+	failureThreshold int
+	openTimeout      time.Duration
+	halfOpenRequests int
+	onStateChange    func(breaker string, from CircuitState, to CircuitState)
+
+	mu         sync.Mutex
+	state      CircuitState
+	generation uint64
+	failures   int
+	successes  int
+	inFlight   int
+	openUntil  time.Time
+
+	now func() time.Time
+}
+
+func (b *circuitBreaker) allow() (uint64, error) {
+	var notify func()
+	b.mu.Lock()
+	defer b.unlock(&notify)
+	if b.state == CircuitOpen {
+		if b.now().Before(b.openUntil) {
+			return 0, &CircuitOpenError{Breaker: b.name, RetryAt: b.openUntil}
+		}
+		notify = b.setState(CircuitHalfOpen)
+	}
+	if b.state == CircuitHalfOpen {
+		if b.inFlight >= b.halfOpenRequests {
+			return 0, &CircuitOpenError{Breaker: b.name}
+		}
+		b.inFlight++
+	}
+	return b.generation, nil
+}
+
+func (b *circuitBreaker) release(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation == b.generation && b.state == CircuitHalfOpen {
+		b.inFlight--
+	}
+}
+
+func (b *circuitBreaker) done(generation uint64, success bool) {
+	var notify func()
+	b.mu.Lock()
+	defer b.unlock(&notify)
+	if generation != b.generation {
+		// the request was allowed in an earlier state
+		return
+	}
+	switch b.state {
+	case CircuitClosed:
+		if success {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.failureThreshold {
+			notify = b.setState(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		b.inFlight--
+		if !success {
+			notify = b.setState(CircuitOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.halfOpenRequests {
+			notify = b.setState(CircuitClosed)
+		}
+	}
+}
+
+// unlock unlocks b.mu and then calls notify, if set, outside the lock
+func (b *circuitBreaker) unlock(notify *func()) {
+	b.mu.Unlock()
+	if *notify != nil {
+		(*notify)()
+	}
+}
+
+// setState moves the breaker to state and starts a new generation, b.mu must be held. It
+// returns the call of onStateChange, to be made after b.mu is unlocked.
+func (b *circuitBreaker) setState(state CircuitState) func() {
+	from := b.state
+	b.state = state
+	b.generation++
+	b.failures = 0
+	b.successes = 0
+	b.inFlight = 0
+	if state == CircuitOpen {
+		b.openUntil = b.now().Add(b.openTimeout)
+	}
+	if b.onStateChange == nil {
+		return nil
+	}
+	return func() {
+		b.onStateChange(b.name, from, state)
+	}
+}
@@ -0,0 +1,194 @@
+package p2pb2b
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stateChange struct {
+	breaker string
+	from    CircuitState
+	to      CircuitState
+}
+
+func TestCircuitBreakerStates(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1574197772, 0)}
+	var changes []stateChange
+	breakers, err := newCircuitBreakers(CircuitBreakerConfig{
+		Trading: CircuitBreakerSettings{FailureThreshold: 2, OpenTimeout: 10 * time.Second, HalfOpenRequests: 2},
+		OnStateChange: func(breaker string, from CircuitState, to CircuitState) {
+			changes = append(changes, stateChange{breaker, from, to})
+		},
+	})
+	assert.Nil(t, err)
+	b := breakers[classPrivate]
+	b.now = clock.Now
+
+	// a success resets the consecutive failures
+	for _, success := range []bool{false, true, false} {
+		generation, err := b.allow()
+		assert.Nil(t, err)
+		b.done(generation, success)
+	}
+	assert.Equal(t, CircuitClosed, b.state)
+
+	generation, _ := b.allow()
+	b.done(generation, false)
+	assert.Equal(t, CircuitOpen, b.state)
+
+	_, err = b.allow()
+	var openErr *CircuitOpenError
+	assert.True(t, errors.As(err, &openErr))
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, "trading", openErr.Breaker)
+	assert.Equal(t, clock.now.Add(10*time.Second), openErr.RetryAt)
+
+	// half-open lets two trial requests through, a failed trial opens again
+	clock.now = clock.now.Add(10 * time.Second)
+	first, err := b.allow()
+	assert.Nil(t, err)
+	second, err := b.allow()
+	assert.Nil(t, err)
+	_, err = b.allow()
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	b.done(first, true)
+	b.done(second, false)
+	assert.Equal(t, CircuitOpen, b.state)
+
+	// two successful trials close the breaker
+	clock.now = clock.now.Add(10 * time.Second)
+	first, _ = b.allow()
+	second, _ = b.allow()
+	b.done(first, true)
+	b.done(second, true)
+	assert.Equal(t, CircuitClosed, b.state)
+
+	assert.Equal(t, []stateChange{
+		{"trading", CircuitClosed, CircuitOpen},
+		{"trading", CircuitOpen, CircuitHalfOpen},
+		{"trading", CircuitHalfOpen, CircuitOpen},
+		{"trading", CircuitOpen, CircuitHalfOpen},
+		{"trading", CircuitHalfOpen, CircuitClosed},
+	}, changes)
+}
+
+func TestCircuitBreakerIgnoresStaleResults(t *testing.T) {
+	breakers, err := newCircuitBreakers(CircuitBreakerConfig{Public: CircuitBreakerSettings{FailureThreshold: 1}})
+	assert.Nil(t, err)
+	b := breakers[classPublic]
+
+	slow, _ := b.allow()
+	fast, _ := b.allow()
+	b.done(fast, false)
+	assert.Equal(t, CircuitOpen, b.state)
+	// a success of a request sent while closed does not close the open breaker
+	b.done(slow, true)
+	assert.Equal(t, CircuitOpen, b.state)
+}
+
+func TestCircuitBreakerInvalidSettings(t *testing.T) {
+	_, err := NewClient("key", "secret", WithCircuitBreaker(CircuitBreakerConfig{Public: CircuitBreakerSettings{FailureThreshold: -1}}))
+	assert.NotNil(t, err)
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	var calls int32
+	status := int32(http.StatusInternalServerError)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		if r.Method == "POST" {
+			w.Write([]byte(`{"success":false,"message":"Insufficient balance.","result":[]}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"message":"","result":[]}`))
+	}))
+	defer ts.Close()
+
+	var changes []stateChange
+	client, err := newClientWithURL(ts.URL, "key", "secret", WithCircuitBreaker(CircuitBreakerConfig{
+		Public: CircuitBreakerSettings{FailureThreshold: 2, OpenTimeout: time.Hour},
+		OnStateChange: func(breaker string, from CircuitState, to CircuitState) {
+			changes = append(changes, stateChange{breaker, from, to})
+		},
+	}))
+	assert.Nil(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = client.GetMarkets()
+		assert.NotNil(t, err)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+	_, err = client.GetMarkets()
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, []stateChange{{"public", CircuitClosed, CircuitOpen}}, changes)
+
+	// the trading breaker is separate, and API errors of a healthy exchange are no failures
+	atomic.StoreInt32(&status, http.StatusOK)
+	for i := 0; i < 10; i++ {
+		_, err = client.PostBalances(&AccountBalancesRequest{})
+		assert.True(t, IsInsufficientFunds(err))
+	}
+
+	// requests canceled by the caller are no failures either
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.PostBalancesCtx(ctx, &AccountBalancesRequest{})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Len(t, changes, 1)
+}
+
+func TestCircuitBreakerCallbackUsesClient(t *testing.T) {
+	status := int32(http.StatusInternalServerError)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		w.Write([]byte(`{"success":true,"message":"","result":[]}`))
+	}))
+	defer ts.Close()
+
+	clock := &fakeClock{now: time.Unix(1574197772, 0)}
+	var c Client
+	var probes []error
+	var err error
+	c, err = newClientWithURL(ts.URL, "key", "secret", WithCircuitBreaker(CircuitBreakerConfig{
+		Public: CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: time.Minute},
+		OnStateChange: func(breaker string, from CircuitState, to CircuitState) {
+			// a health probe on the same breaker must not deadlock
+			_, err := c.GetMarkets()
+			probes = append(probes, err)
+		},
+	}))
+	assert.Nil(t, err)
+	c.(*client).breakers[classPublic].now = clock.Now
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err = c.GetMarkets()
+		assert.NotNil(t, err)
+
+		atomic.StoreInt32(&status, http.StatusOK)
+		clock.now = clock.now.Add(time.Minute)
+		_, err = c.GetMarkets()
+		assert.Nil(t, err)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("state change callback deadlocked")
+	}
+	if assert.Len(t, probes, 3) {
+		// open, half-open with the trial in flight, closed
+		assert.True(t, errors.Is(probes[0], ErrCircuitOpen))
+		assert.True(t, errors.Is(probes[1], ErrCircuitOpen))
+		assert.Nil(t, probes[2])
+	}
+}
@@ -0,0 +1,142 @@
+package p2pb2b
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is the cache window assumed for public endpoints without a TTL override
+const DefaultCacheTTL = time.Second
+
+// CacheConfig configures a ResponseCache
+type CacheConfig struct {
+	// DefaultTTL is the length of the server side cache window starting at cache_time,
+	// DefaultCacheTTL if 0
+	DefaultTTL time.Duration
+	// TTL overrides DefaultTTL per endpoint name, e.g. "/public/markets". A TTL of 0
+	// disables caching for the endpoint.
+	TTL map[string]time.Duration
+}
+
+// CacheStats are the statistics of a ResponseCache
+type CacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// ResponseCache is an in-memory cache of successful public GET responses keyed by URL.
+// A response is served until the cache window of the exchange expires: the window starts
+// at the advertised cache_time and lasts the TTL of the endpoint, the age of the response
+// is taken from current_time. A ResponseCache can be shared by several clients.
+type ResponseCache struct {
+	mu      sync.Mutex
+	config  CacheConfig
+	entries map[string]cacheEntry
+	hits    uint64
+	misses  uint64
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// NewResponseCache creates an empty ResponseCache
+func NewResponseCache(config CacheConfig) *ResponseCache {
+	if config.DefaultTTL <= 0 {
+		config.DefaultTTL = DefaultCacheTTL
+	}
+	return &ResponseCache{
+		config:  config,
+		entries: map[string]cacheEntry{},
+		now:     time.Now,
+	}
+}
+
+// Stats returns the number of hits, misses and cached entries
+func (c *ResponseCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Hits: c.hits, Misses: c.misses, Entries: len(c.entries)}
+}
+
+// Purge removes all entries
+func (c *ResponseCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]cacheEntry{}
+}
+
+func (c *ResponseCache) ttl(endpoint string) time.Duration {
+	if ttl, ok := c.config.TTL[endpoint]; ok {
+		return ttl
+	}
+	return c.config.DefaultTTL
+}
+
+// get returns a cached response for url, counting a hit or a miss
+func (c *ResponseCache) get(url string, endpoint string) (*response, bool) {
+	if c.ttl(endpoint) <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[url]
+	if ok && !c.now().Before(entry.expires) {
+		delete(c.entries, url)
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	return &response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     entry.header.Clone(),
+		Body:       ioutil.NopCloser(bytes.NewReader(entry.body)),
+		cached:     true,
+	}, true
+}
+
+// cacheWindow is the part of public responses describing the server side cache
+type cacheWindow struct {
+	Success     bool    `json:"success"`
+	CacheTime   float64 `json:"cache_time"`
+	CurrentTime float64 `json:"current_time"`
+}
+
+// put stores a successful response body for url
+func (c *ResponseCache) put(url string, endpoint string, header http.Header, body []byte) {
+	ttl := c.ttl(endpoint)
+	if ttl <= 0 {
+		return
+	}
+	var window cacheWindow
+	if err := json.Unmarshal(body, &window); err != nil || !window.Success {
+		return
+	}
+	if window.CacheTime > 0 && window.CurrentTime >= window.CacheTime {
+		ttl -= time.Duration((window.CurrentTime - window.CacheTime) * float64(time.Second))
+	}
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[url] = cacheEntry{
+		header:  header.Clone(),
+		body:    body,
+		expires: c.now().Add(ttl),
+	}
+}
@@ -0,0 +1,113 @@
+package p2pb2b
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseCache(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":["ETH","BTC"],"cache_time":1574197772.0,"current_time":1574197772.5}`))
+	}))
+	defer ts.Close()
+
+	clock := &fakeClock{now: time.Unix(1574197772, 0)}
+	cache := NewResponseCache(CacheConfig{DefaultTTL: 2 * time.Second})
+	cache.now = clock.Now
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithResponseCache(cache))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.GetSymbols()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"ETH", "BTC"}, resp.Result)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1, Entries: 1}, cache.Stats())
+
+	// the response was 500ms old, so the 2s window expires after 1.5s
+	clock.now = clock.now.Add(1400 * time.Millisecond)
+	_, err = client.GetSymbols()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	clock.now = clock.now.Add(200 * time.Millisecond)
+	_, err = client.GetSymbols()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, CacheStats{Hits: 3, Misses: 2, Entries: 1}, cache.Stats())
+
+	cache.Purge()
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
+
+func TestResponseCacheKeyedByURLAndOverrides(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":{}}`))
+	}))
+	defer ts.Close()
+
+	cache := NewResponseCache(CacheConfig{
+		DefaultTTL: time.Minute,
+		TTL:        map[string]time.Duration{"/public/depth/result": 0},
+	})
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithResponseCache(cache))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	client.GetTicker("ETH_BTC")
+	client.GetTicker("ETH_BTC")
+	client.GetTicker("BTC_USD")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// caching is disabled for depth results
+	client.GetDepthResult("ETH_BTC", 10)
+	client.GetDepthResult("ETH_BTC", 10)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	// private endpoints are never cached
+	client.PostBalances(&AccountBalancesRequest{})
+	client.PostBalances(&AccountBalancesRequest{})
+	assert.Equal(t, int32(6), atomic.LoadInt32(&calls))
+}
+
+func TestResponseCacheSkipsFailures(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":false,"message":"market not found","result":[]}`))
+	}))
+	defer ts.Close()
+
+	cache := NewResponseCache(CacheConfig{DefaultTTL: time.Minute})
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithResponseCache(cache))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	_, err = client.GetMarkets()
+	assert.NotNil(t, err)
+	_, err = client.GetMarkets()
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, 0, cache.Stats().Entries)
+}
@@ -0,0 +1,269 @@
+// Package cassette records HTTP interactions with the p2pb2b API to fixture files and
+// replays them, so code using the p2pb2b client can be tested offline against captured
+// exchange behaviour. A Recorder is an http.RoundTripper, plug it into a client with
+// p2pb2b.WithTransport.
+//
+// API keys, signatures and payloads are scrubbed before a cassette is saved, and the
+// nonce of signed requests is ignored when matching, so replays are deterministic.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	p2pb2b "github.com/krinklesaurus/go-p2pb2b"
+)
+
+// Mode is the mode of a Recorder
+type Mode int
+
+const (
+	// Replay serves the interactions of an existing cassette and fails on unexpected requests
+	Replay Mode = iota
+	// Record sends requests to the exchange and saves the interactions on Stop
+	Record
+)
+
+// ErrUnexpectedRequest is returned in replay mode for requests not found in the cassette
+var ErrUnexpectedRequest = errors.New("unexpected request")
+
+const scrubbed = "[SCRUBBED]"
+
+// scrubbedHeaders are never saved to a cassette
+var scrubbedHeaders = []string{p2pb2b.HeaderXTxcAPIKey, p2pb2b.HeaderXTxcSignature, p2pb2b.HeaderXTxcPayloard, "Authorization"}
+
+// scrubbedFields are replaced in JSON request bodies, they change with every request
+var scrubbedFields = []string{"nonce"}
+
+// Cassette is the content of a fixture file
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a scrubbed HTTP request. URL is the path and query without scheme
+// and host, so cassettes can be replayed against any base URL.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is an HTTP response
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// Load reads a cassette from path
+func Load(path string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cassette %s, %v", path, err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("error parsing cassette %s, %v", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding cassette, %v", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing cassette %s, %v", path, err)
+	}
+	return nil
+}
+
+// Recorder records or replays the HTTP interactions of a cassette file
+type Recorder struct {
+	mode      Mode
+	path      string
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// New creates a Recorder for the cassette at path. In Record mode requests are sent
+// through transport, http.DefaultTransport if nil, and the cassette is written by Stop.
+// In Replay mode the cassette must exist and transport is not used.
+func New(path string, mode Mode, transport http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{mode: mode, path: path, transport: transport}
+	switch mode {
+	case Record:
+		if r.transport == nil {
+			r.transport = http.DefaultTransport
+		}
+		r.cassette = &Cassette{}
+	case Replay:
+		c, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = c
+		r.used = make([]bool, len(c.Interactions))
+	default:
+		return nil, fmt.Errorf("unknown cassette mode %d", mode)
+	}
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == Replay {
+		return r.replay(req, recorded)
+	}
+	return r.record(req, recorded)
+}
+
+func (r *Recorder) record(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading response of %s, %v", recorded.URL, err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(resp.Header),
+			Body:       string(body),
+		},
+	})
+	return resp, nil
+}
+
+// replay serves the first unused interaction matching recorded
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !matches(interaction.Request, recorded) {
+			continue
+		}
+		r.used[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(interaction.Response.Body))),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%s %s with body %q not in cassette %s, %w", recorded.Method, recorded.URL, recorded.Body, r.path, ErrUnexpectedRequest)
+}
+
+// Unused returns the number of interactions not replayed yet
+func (r *Recorder) Unused() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, used := range r.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+// Stop saves the recorded interactions in Record mode, it does nothing in Replay mode
+func (r *Recorder) Stop() error {
+	if r.mode != Record {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+func matches(a RecordedRequest, b RecordedRequest) bool {
+	return a.Method == b.Method && a.URL == b.URL && a.Body == b.Body
+}
+
+// recordRequest returns the scrubbed recording of req, req.Body can still be read afterwards
+func recordRequest(req *http.Request) (RecordedRequest, error) {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		URL:    req.URL.RequestURI(),
+		Header: scrubHeader(req.Header),
+	}
+	if req.Body == nil {
+		return recorded, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return RecordedRequest{}, fmt.Errorf("error reading request body, %v", err)
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	recorded.Body = scrubBody(body)
+	return recorded, nil
+}
+
+// scrubHeader returns a copy of header with credentials and signatures replaced
+func scrubHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	result := header.Clone()
+	for _, h := range scrubbedHeaders {
+		if result.Get(h) != "" {
+			result.Set(h, scrubbed)
+		}
+	}
+	return result
+}
+
+// scrubBody replaces scrubbedFields of a JSON object body, other bodies are kept as they are
+func scrubBody(body []byte) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return string(body)
+	}
+	for _, f := range scrubbedFields {
+		if _, ok := fields[f]; ok {
+			fields[f] = json.RawMessage(`"` + scrubbed + `"`)
+		}
+	}
+	// map keys are marshaled sorted, so equal bodies are recorded equally
+	scrubbedBody, err := json.Marshal(fields)
+	if err != nil {
+		return string(body)
+	}
+	return string(scrubbedBody)
+}
@@ -0,0 +1,87 @@
+package cassette
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	p2pb2b "github.com/krinklesaurus/go-p2pb2b"
+	"github.com/stretchr/testify/assert"
+)
+
+func newExchange() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/public/ticker":
+			w.Write([]byte(`{"success":true,"message":"","result":{"bid":"0.021","ask":"0.022","open":"0.02","high":"0.023","low":"0.019","last":"0.021","volume":"10","deal":"0.2","change":"1"},"cache_time":1574197469.668139,"current_time":1574197469.668141}`))
+		case "/account/balances":
+			w.Write([]byte(`{"success":true,"message":"","result":{"ETH":{"available":"0.1","freeze":"0.4"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "p2pb2b-cassette")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+	ts := newExchange()
+
+	recorder, err := New(path, Record, nil)
+	assert.Nil(t, err)
+	client, err := p2pb2b.NewClient("my-api-key", "my-api-secret", p2pb2b.WithBaseURL(ts.URL), p2pb2b.WithTransport(recorder))
+	assert.Nil(t, err)
+	recordedTicker, err := client.GetTicker("ETH_BTC")
+	assert.Nil(t, err)
+	_, err = client.PostBalances(&p2pb2b.AccountBalancesRequest{})
+	assert.Nil(t, err)
+	assert.Nil(t, recorder.Stop())
+	ts.Close()
+
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.False(t, strings.Contains(string(data), "my-api-key"))
+	assert.True(t, strings.Contains(string(data), scrubbed))
+
+	// replay against an unreachable host with different credentials and nonces
+	replayer, err := New(path, Replay, nil)
+	assert.Nil(t, err)
+	client, err = p2pb2b.NewClient("other-key", "other-secret", p2pb2b.WithBaseURL("http://127.0.0.1:1"), p2pb2b.WithTransport(replayer))
+	assert.Nil(t, err)
+	ticker, err := client.GetTicker("ETH_BTC")
+	assert.Nil(t, err)
+	assert.Equal(t, recordedTicker.Result, ticker.Result)
+	assert.Equal(t, recordedTicker.CurrentTime, ticker.CurrentTime)
+	assert.Equal(t, recordedTicker.Meta().Body, ticker.Meta().Body)
+	balances, err := client.PostBalances(&p2pb2b.AccountBalancesRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, "0.4", balances.Result["ETH"].Freeze.String())
+	assert.Equal(t, 0, replayer.Unused())
+
+	// every interaction is replayed once
+	_, err = client.GetTicker("ETH_BTC")
+	assert.True(t, errors.Is(err, ErrUnexpectedRequest))
+	_, err = client.GetTicker("LTC_BTC")
+	assert.True(t, errors.Is(err, ErrUnexpectedRequest))
+}
+
+func TestReplayMissingCassette(t *testing.T) {
+	dir, err := ioutil.TempDir("", "p2pb2b-cassette")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	_, err = New(filepath.Join(dir, "missing.json"), Replay, nil)
+	assert.NotNil(t, err)
+}
+
+func TestScrubBody(t *testing.T) {
+	assert.Equal(t, `{"market":"ETH_BTC","nonce":"[SCRUBBED]"}`, scrubBody([]byte(`{"nonce":"1574197469668","market":"ETH_BTC"}`)))
+	assert.Equal(t, "not json", scrubBody([]byte("not json")))
+}
@@ -0,0 +1,91 @@
+package p2pb2b
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// clockSamples is the number of recent samples a ServerClock chooses its offset from
+const clockSamples = 8
+
+// ServerClock estimates the offset between the local clock and the clock of the exchange
+// from the current_time field of public responses. Like NTP it compensates the round trip
+// time by assuming the server time was taken halfway between sending and receiving, and
+// uses the offset of the recent sample with the lowest round trip time.
+type ServerClock struct {
+	mu      sync.RWMutex
+	samples []clockSample
+	offset  time.Duration
+	synced  bool
+	now     func() time.Time
+}
+
+type clockSample struct {
+	offset time.Duration
+	rtt    time.Duration
+}
+
+// NewServerClock creates an unsynchronized ServerClock, whose offset is 0 until the first observation
+func NewServerClock() *ServerClock {
+	return &ServerClock{now: time.Now}
+}
+
+// Observe records serverTime taken from a response to a request sent at sent and received at received
+func (c *ServerClock) Observe(serverTime time.Time, sent time.Time, received time.Time) {
+	rtt := received.Sub(sent)
+	if rtt < 0 || serverTime.IsZero() {
+		return
+	}
+	sample := clockSample{
+		offset: serverTime.Sub(sent.Add(rtt / 2)),
+		rtt:    rtt,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.samples = append(c.samples, sample)
+	if len(c.samples) > clockSamples {
+		c.samples = c.samples[len(c.samples)-clockSamples:]
+	}
+	best := c.samples[0]
+	for _, s := range c.samples[1:] {
+		if s.rtt <= best.rtt {
+			best = s
+		}
+	}
+	c.offset = best.offset
+	c.synced = true
+}
+
+// Offset returns the estimated difference of server time minus local time
+func (c *ServerClock) Offset() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.offset
+}
+
+// Synced reports whether the clock has observed at least one server time
+func (c *ServerClock) Synced() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.synced
+}
+
+// ServerTime returns the estimated current time of the exchange
+func (c *ServerClock) ServerTime() time.Time {
+	return c.now().Add(c.Offset())
+}
+
+// currentTime returns the current_time field of a decoded response, 0 if it has none
+func currentTime(result interface{}) float64 {
+	v := reflect.ValueOf(result)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return 0
+	}
+	field := v.Elem().FieldByName("CurrentTime")
+	if !field.IsValid() || field.Kind() != reflect.Float64 {
+		return 0
+	}
+	return field.Float()
+}
@@ -0,0 +1,92 @@
+package p2pb2b
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerClockObserve(t *testing.T) {
+	local := &fakeClock{now: time.Unix(1574197772, 0)}
+	clock := NewServerClock()
+	clock.now = local.Now
+
+	assert.False(t, clock.Synced())
+	assert.Equal(t, local.now, clock.ServerTime())
+
+	// server is 5s ahead, the server time was taken halfway through a 200ms round trip
+	sent := local.now
+	clock.Observe(sent.Add(5*time.Second+100*time.Millisecond), sent, sent.Add(200*time.Millisecond))
+	assert.True(t, clock.Synced())
+	assert.Equal(t, 5*time.Second, clock.Offset())
+	assert.Equal(t, local.now.Add(5*time.Second), clock.ServerTime())
+
+	// a slower sample is less accurate and does not replace the estimate
+	clock.Observe(sent.Add(7*time.Second), sent, sent.Add(2*time.Second))
+	assert.Equal(t, 5*time.Second, clock.Offset())
+
+	// a faster sample does
+	clock.Observe(sent.Add(4*time.Second+25*time.Millisecond), sent, sent.Add(50*time.Millisecond))
+	assert.Equal(t, 4*time.Second, clock.Offset())
+
+	// invalid samples are ignored
+	clock.Observe(sent, sent, sent.Add(-time.Second))
+	clock.Observe(time.Time{}, sent, sent)
+	assert.Equal(t, 4*time.Second, clock.Offset())
+}
+
+func TestServerClockForgetsOldSamples(t *testing.T) {
+	clock := NewServerClock()
+	sent := time.Unix(1574197772, 0)
+	clock.Observe(sent.Add(time.Second), sent, sent)
+	for i := 0; i < clockSamples; i++ {
+		clock.Observe(sent.Add(2*time.Second+50*time.Millisecond), sent, sent.Add(100*time.Millisecond))
+	}
+	assert.Equal(t, 2*time.Second, clock.Offset())
+}
+
+func TestWithServerClockFeedsNonces(t *testing.T) {
+	serverTime := time.Now().Add(time.Hour)
+	var nonces []int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			var request Request
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&request))
+			nonce, err := strconv.ParseInt(request.Nonce, 10, 64)
+			assert.Nil(t, err)
+			nonces = append(nonces, nonce)
+			w.Write([]byte(`{"success":true,"message":"","result":{}}`))
+			return
+		}
+		current := float64(serverTime.UnixNano()) / 1e9
+		w.Write([]byte(fmt.Sprintf(`{"success":true,"message":"","result":{},"cache_time":%f,"current_time":%f}`, current, current)))
+	}))
+	defer ts.Close()
+
+	clock := NewServerClock()
+	client, err := newClientWithURL(ts.URL, "key", "secret", WithServerClock(clock))
+	assert.Nil(t, err)
+
+	_, err = client.GetTicker("ETH_BTC")
+	assert.Nil(t, err)
+	assert.True(t, clock.Synced())
+	assert.InDelta(t, time.Hour.Seconds(), clock.Offset().Seconds(), 1)
+
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	assert.Nil(t, err)
+	if assert.Len(t, nonces, 1) {
+		assert.InDelta(t, serverTime.UnixNano()/int64(time.Millisecond), nonces[0], 1000)
+	}
+}
+
+func TestCurrentTime(t *testing.T) {
+	assert.Equal(t, 1574193389.5758, currentTime(&TickerResp{CurrentTime: 1574193389.5758}))
+	assert.Equal(t, float64(0), currentTime(&AccountBalancesResp{}))
+	assert.Equal(t, float64(0), currentTime(nil))
+}
@@ -0,0 +1,104 @@
+package p2pb2b
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+)
+
+// CoalescerStats are the statistics of a RequestCoalescer
+type CoalescerStats struct {
+	// Calls is the number of public calls passed through the coalescer
+	Calls uint64
+	// Coalesced is the number of calls which shared the request of a concurrent identical call
+	Coalesced uint64
+	// InFlight is the number of requests currently in flight
+	InFlight int
+}
+
+// RequestCoalescer lets concurrent identical public GET requests share one HTTP request.
+// The response body is read once and every caller decodes it into its own result, so the
+// results are equal but not shared. A RequestCoalescer can be shared by several clients.
+type RequestCoalescer struct {
+	mu        sync.Mutex
+	flights   map[string]*flight
+	calls     uint64
+	coalesced uint64
+}
+
+// flight is a request in flight, resp and err are set when done is closed
+type flight struct {
+	done chan struct{}
+	resp *response
+	body []byte
+	err  error
+}
+
+// NewRequestCoalescer creates a RequestCoalescer
+func NewRequestCoalescer() *RequestCoalescer {
+	return &RequestCoalescer{flights: map[string]*flight{}}
+}
+
+// Stats returns the number of calls, coalesced calls and requests in flight
+func (rc *RequestCoalescer) Stats() CoalescerStats {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return CoalescerStats{Calls: rc.calls, Coalesced: rc.coalesced, InFlight: len(rc.flights)}
+}
+
+// do calls send unless a call with the same key is in flight, whose response is shared
+// instead. Every caller gets a response with its own reader of the body.
+func (rc *RequestCoalescer) do(ctx context.Context, key string, limit int64, send func() (*response, error)) (*response, error) {
+	rc.mu.Lock()
+	rc.calls++
+	if f, ok := rc.flights[key]; ok {
+		rc.coalesced++
+		rc.mu.Unlock()
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if f.err != nil && isContextErr(f.err) && ctx.Err() == nil {
+			// the shared request was canceled by its caller, not by this one
+			return send()
+		}
+		return f.response(true)
+	}
+	f := &flight{done: make(chan struct{})}
+	rc.flights[key] = f
+	rc.mu.Unlock()
+
+	f.resp, f.err = send()
+	if f.err == nil {
+		f.body, f.err = readLimited(f.resp.Body, limit)
+		f.resp.Body.Close()
+		if f.err != nil {
+			f.err = fmt.Errorf("error reading response of %s, %w", f.resp.Endpoint, f.err)
+		}
+	}
+
+	rc.mu.Lock()
+	delete(rc.flights, key)
+	rc.mu.Unlock()
+	close(f.done)
+	return f.response(false)
+}
+
+func (f *flight) response(coalesced bool) (*response, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	resp := *f.resp
+	resp.Header = f.resp.Header.Clone()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(f.body))
+	resp.coalesced = coalesced
+	return &resp, nil
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,121 @@
+package p2pb2b
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingServer answers ticker requests once release is closed
+func blockingServer(calls *int32, release chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte(`{"success":true,"message":"","result":{"bid":"0.021","ask":"0.022","last":"0.021"},"cache_time":1574197469.668139,"current_time":1574197469.668141}`))
+	}))
+}
+
+// waitFor polls condition until it holds or the test times out
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRequestCoalescer(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	ts := blockingServer(&calls, release)
+	defer ts.Close()
+
+	coalescer := NewRequestCoalescer()
+	clock := NewServerClock()
+	client, err := newClientWithURL(ts.URL, "key", "secret", WithRequestCoalescer(coalescer), WithServerClock(clock))
+	assert.Nil(t, err)
+
+	const callers = 5
+	results := make([]*TickerResp, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := client.GetTicker("ETH_BTC")
+			assert.Nil(t, err)
+			results[i] = resp
+		}(i)
+	}
+	waitFor(t, func() bool { return coalescer.Stats().Coalesced == callers-1 })
+	assert.Equal(t, CoalescerStats{Calls: callers, Coalesced: callers - 1, InFlight: 1}, coalescer.Stats())
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, 0, coalescer.Stats().InFlight)
+	coalesced := 0
+	for _, resp := range results {
+		if assert.NotNil(t, resp) {
+			assert.Equal(t, "0.021", resp.Result.Bid.String())
+			assert.Equal(t, results[0].Result, resp.Result)
+			if resp.Meta().Coalesced {
+				coalesced++
+			}
+		}
+	}
+	assert.Equal(t, callers-1, coalesced)
+	// the shared response is one sample of the server clock, not one per caller
+	assert.Len(t, clock.samples, 1)
+
+	// calls which are not concurrent are not coalesced
+	_, err = client.GetTicker("ETH_BTC")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Len(t, clock.samples, 2)
+}
+
+func TestRequestCoalescerLeaderCanceled(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	ts := blockingServer(&calls, release)
+	defer ts.Close()
+
+	coalescer := NewRequestCoalescer()
+	client, err := newClientWithURL(ts.URL, "key", "secret", WithRequestCoalescer(coalescer))
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan error)
+	go func() {
+		_, err := client.GetTickerCtx(ctx, "ETH_BTC")
+		leaderDone <- err
+	}()
+	waitFor(t, func() bool { return atomic.LoadInt32(&calls) == 1 })
+
+	followerDone := make(chan error)
+	go func() {
+		_, err := client.GetTicker("ETH_BTC")
+		followerDone <- err
+	}()
+	waitFor(t, func() bool { return coalescer.Stats().Coalesced == 1 })
+
+	// the follower sends its own request when the shared one is canceled
+	cancel()
+	assert.NotNil(t, <-leaderDone)
+	waitFor(t, func() bool { return atomic.LoadInt32(&calls) == 2 })
+	close(release)
+	assert.Nil(t, <-followerDone)
+}
@@ -0,0 +1,169 @@
+package p2pb2b
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// EnvAPIKey is the default environment variable read by EnvCredentials for the API key
+	EnvAPIKey = "P2PB2B_API_KEY"
+	// EnvAPISecret is the default environment variable read by EnvCredentials for the API secret
+	EnvAPISecret = "P2PB2B_API_SECRET"
+)
+
+// ErrNoCredentials is returned by credentials providers which have no credentials
+var ErrNoCredentials = errors.New("no credentials")
+
+// Credentials are an API key and secret of the exchange
+type Credentials struct {
+	APIKey    string `json:"apiKey" yaml:"apiKey"`
+	APISecret string `json:"apiSecret" yaml:"apiSecret"`
+}
+
+// CredentialsProvider provides the credentials of the client. It is called before every
+// private request, so implementations can rotate keys at runtime.
+type CredentialsProvider interface {
+	Credentials() (Credentials, error)
+}
+
+type staticCredentials Credentials
+
+// StaticCredentials returns a CredentialsProvider always returning apiKey and apiSecret
+func StaticCredentials(apiKey string, apiSecret string) CredentialsProvider {
+	return staticCredentials{APIKey: apiKey, APISecret: apiSecret}
+}
+
+func (s staticCredentials) Credentials() (Credentials, error) {
+	return Credentials(s), nil
+}
+
+type envCredentials struct {
+	keyVar    string
+	secretVar string
+}
+
+// EnvCredentials returns a CredentialsProvider reading the environment variables keyVar
+// and secretVar, EnvAPIKey and EnvAPISecret if empty
+func EnvCredentials(keyVar string, secretVar string) CredentialsProvider {
+	if keyVar == "" {
+		keyVar = EnvAPIKey
+	}
+	if secretVar == "" {
+		secretVar = EnvAPISecret
+	}
+	return &envCredentials{keyVar: keyVar, secretVar: secretVar}
+}
+
+func (e *envCredentials) Credentials() (Credentials, error) {
+	creds := Credentials{
+		APIKey:    os.Getenv(e.keyVar),
+		APISecret: os.Getenv(e.secretVar),
+	}
+	if creds.APIKey == "" || creds.APISecret == "" {
+		return Credentials{}, fmt.Errorf("environment variables %s and %s must be set, %w", e.keyVar, e.secretVar, ErrNoCredentials)
+	}
+	return creds, nil
+}
+
+type fileCredentials struct {
+	mu      sync.Mutex
+	path    string
+	modTime time.Time
+	creds   Credentials
+}
+
+// FileCredentials returns a CredentialsProvider reading apiKey and apiSecret from a JSON
+// file, or a YAML file if path ends with .yaml or .yml. The file is read again whenever its
+// modification time changes, so keys can be rotated by replacing the file.
+func FileCredentials(path string) CredentialsProvider {
+	return &fileCredentials{path: path}
+}
+
+func (f *fileCredentials) Credentials() (Credentials, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("error reading credentials file, %v", err)
+	}
+	if info.ModTime().Equal(f.modTime) {
+		return f.creds, nil
+	}
+
+	content, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("error reading credentials file, %v", err)
+	}
+	var creds Credentials
+	switch strings.ToLower(filepath.Ext(f.path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &creds)
+	default:
+		err = json.Unmarshal(content, &creds)
+	}
+	if err != nil {
+		return Credentials{}, fmt.Errorf("error parsing credentials file %s, %v", f.path, err)
+	}
+	if creds.APIKey == "" || creds.APISecret == "" {
+		return Credentials{}, fmt.Errorf("credentials file %s must contain apiKey and apiSecret, %w", f.path, ErrNoCredentials)
+	}
+	f.creds = creds
+	f.modTime = info.ModTime()
+	return creds, nil
+}
+
+type chainCredentials []CredentialsProvider
+
+// ChainCredentials returns a CredentialsProvider returning the credentials of the first
+// provider which has some, e.g. ChainCredentials(EnvCredentials("", ""), FileCredentials(path))
+func ChainCredentials(providers ...CredentialsProvider) CredentialsProvider {
+	return chainCredentials(providers)
+}
+
+func (c chainCredentials) Credentials() (Credentials, error) {
+	var errs []string
+	for _, provider := range c {
+		creds, err := provider.Credentials()
+		if err == nil {
+			return creds, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return Credentials{}, fmt.Errorf("no provider returned credentials (%s), %w", strings.Join(errs, "; "), ErrNoCredentials)
+}
+
+// RotatingCredentials is a CredentialsProvider whose credentials can be replaced at runtime
+type RotatingCredentials struct {
+	mu    sync.RWMutex
+	creds Credentials
+}
+
+// NewRotatingCredentials creates RotatingCredentials starting with apiKey and apiSecret
+func NewRotatingCredentials(apiKey string, apiSecret string) *RotatingCredentials {
+	return &RotatingCredentials{creds: Credentials{APIKey: apiKey, APISecret: apiSecret}}
+}
+
+// Rotate replaces the credentials, they are used for all following requests
+func (r *RotatingCredentials) Rotate(apiKey string, apiSecret string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.creds = Credentials{APIKey: apiKey, APISecret: apiSecret}
+}
+
+// Credentials implements CredentialsProvider
+func (r *RotatingCredentials) Credentials() (Credentials, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.creds, nil
+}
@@ -0,0 +1,115 @@
+package p2pb2b
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvCredentials(t *testing.T) {
+	os.Setenv("P2PB2B_TEST_KEY", "envKey")
+	os.Setenv("P2PB2B_TEST_SECRET", "envSecret")
+	defer os.Unsetenv("P2PB2B_TEST_KEY")
+	defer os.Unsetenv("P2PB2B_TEST_SECRET")
+
+	creds, err := EnvCredentials("P2PB2B_TEST_KEY", "P2PB2B_TEST_SECRET").Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{APIKey: "envKey", APISecret: "envSecret"}, creds)
+
+	_, err = EnvCredentials("P2PB2B_TEST_MISSING", "P2PB2B_TEST_SECRET").Credentials()
+	assert.True(t, errors.Is(err, ErrNoCredentials))
+}
+
+func TestFileCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "p2pb2b-credentials")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	jsonPath := filepath.Join(dir, "credentials.json")
+	assert.Nil(t, ioutil.WriteFile(jsonPath, []byte(`{"apiKey":"jsonKey","apiSecret":"jsonSecret"}`), 0600))
+	yamlPath := filepath.Join(dir, "credentials.yaml")
+	assert.Nil(t, ioutil.WriteFile(yamlPath, []byte("apiKey: yamlKey\napiSecret: yamlSecret\n"), 0600))
+
+	provider := FileCredentials(jsonPath)
+	creds, err := provider.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{APIKey: "jsonKey", APISecret: "jsonSecret"}, creds)
+
+	creds, err = FileCredentials(yamlPath).Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{APIKey: "yamlKey", APISecret: "yamlSecret"}, creds)
+
+	// replacing the file rotates the credentials
+	assert.Nil(t, ioutil.WriteFile(jsonPath, []byte(`{"apiKey":"newKey","apiSecret":"newSecret"}`), 0600))
+	future := time.Now().Add(time.Minute)
+	assert.Nil(t, os.Chtimes(jsonPath, future, future))
+	creds, err = provider.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, Credentials{APIKey: "newKey", APISecret: "newSecret"}, creds)
+
+	_, err = FileCredentials(filepath.Join(dir, "missing.json")).Credentials()
+	assert.NotNil(t, err)
+
+	incompletePath := filepath.Join(dir, "incomplete.yml")
+	assert.Nil(t, ioutil.WriteFile(incompletePath, []byte("apiKey: yamlKey\n"), 0600))
+	_, err = FileCredentials(incompletePath).Credentials()
+	assert.True(t, errors.Is(err, ErrNoCredentials))
+}
+
+func TestChainCredentials(t *testing.T) {
+	chain := ChainCredentials(
+		EnvCredentials("P2PB2B_TEST_MISSING", "P2PB2B_TEST_MISSING"),
+		StaticCredentials("staticKey", "staticSecret"),
+	)
+	creds, err := chain.Credentials()
+	assert.Nil(t, err)
+	assert.Equal(t, "staticKey", creds.APIKey)
+
+	_, err = ChainCredentials(EnvCredentials("P2PB2B_TEST_MISSING", "P2PB2B_TEST_MISSING")).Credentials()
+	assert.True(t, errors.Is(err, ErrNoCredentials))
+}
+
+func TestRotatingCredentials(t *testing.T) {
+	var keys []string
+	var signatures []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(HeaderXTxcAPIKey))
+		signatures = append(signatures, r.Header.Get(HeaderXTxcSignature))
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":{}}`))
+	}))
+	defer ts.Close()
+
+	rotating := NewRotatingCredentials("firstKey", "firstSecret")
+	client, err := newClientWithURL(ts.URL, "", "", WithCredentialsProvider(rotating))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	request := &AccountBalancesRequest{Request: Request{Request: "/account/balances", Nonce: "1"}}
+	_, err = client.PostBalances(request)
+	assert.Nil(t, err)
+	rotating.Rotate("secondKey", "secondSecret")
+	_, err = client.PostBalances(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"firstKey", "secondKey"}, keys)
+	assert.NotEqual(t, signatures[0], signatures[1])
+}
+
+func TestCredentialsProviderError(t *testing.T) {
+	client, err := NewClient("", "", WithCredentialsProvider(EnvCredentials("P2PB2B_TEST_MISSING", "P2PB2B_TEST_MISSING")))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	assert.True(t, errors.Is(err, ErrNoCredentials))
+}
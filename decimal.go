@@ -0,0 +1,340 @@
+package p2pb2b
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal is an arbitrary precision decimal number for prices, amounts and fees. It keeps
+// the number of decimal places it was created with, so values decoded from the exchange
+// are encoded again exactly as received, e.g. "0.10" stays "0.10". Decimals are encoded
+// as JSON strings and decoded from JSON strings or numbers. The zero value is 0.
+//
+// Decimals are immutable; arithmetic methods return new values.
+type Decimal struct {
+	// coef is the unscaled value, nil for 0
+	coef *big.Int
+	// scale is the number of decimal places, the value is coef * 10^-scale
+	scale int32
+}
+
+// maxDecimalExponent bounds the exponent and the decimal places of parsed decimals, so a
+// short input like "1e100000000" cannot make parsing or formatting allocate huge numbers
+const maxDecimalExponent = 1000
+
+var (
+	bigTen = big.NewInt(10)
+	bigOne = big.NewInt(1)
+)
+
+func newDecimal(coef *big.Int, scale int32) Decimal {
+	if coef.Sign() == 0 {
+		coef = nil
+	}
+	return Decimal{coef: coef, scale: scale}
+}
+
+// NewDecimal returns unscaled * 10^-scale, e.g. NewDecimal(15, 1) is 1.5. It panics if
+// scale is not between -1000 and 1000.
+func NewDecimal(unscaled int64, scale int32) Decimal {
+	if scale > maxDecimalExponent || scale < -maxDecimalExponent {
+		panic(fmt.Sprintf("p2pb2b: Decimal scale %d out of range", scale))
+	}
+	coef := big.NewInt(unscaled)
+	if scale < 0 {
+		coef.Mul(coef, pow10(-scale))
+		scale = 0
+	}
+	return newDecimal(coef, scale)
+}
+
+// NewDecimalFromInt returns i as Decimal
+func NewDecimalFromInt(i int64) Decimal {
+	return NewDecimal(i, 0)
+}
+
+// NewDecimalFromFloat returns the shortest decimal representation of f, e.g. 0.1 for
+// 0.1. It panics if f is NaN or infinite.
+func NewDecimalFromFloat(f float64) Decimal {
+	d, err := ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		panic(fmt.Sprintf("p2pb2b: cannot convert %v to Decimal", f))
+	}
+	return d
+}
+
+// ParseDecimal parses a decimal number like "12", "-0.00012345" or "1.5e-8". The exponent
+// and the number of decimal places must not exceed 1000.
+func ParseDecimal(s string) (Decimal, error) {
+	mantissa, exponent := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		exponent, err = strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil || exponent > maxDecimalExponent || exponent < -maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+		}
+		mantissa = s[:i]
+	}
+	sign := ""
+	if strings.HasPrefix(mantissa, "-") || strings.HasPrefix(mantissa, "+") {
+		sign, mantissa = mantissa[:1], mantissa[1:]
+	}
+	intPart, fracPart := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		intPart, fracPart = mantissa[:i], mantissa[i+1:]
+	}
+	digits := intPart + fracPart
+	if digits == "" || strings.TrimLeft(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	coef, ok := new(big.Int).SetString(sign+digits, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+	}
+	scale := int64(len(fracPart)) - exponent
+	if scale > maxDecimalExponent {
+		return Decimal{}, fmt.Errorf("invalid decimal %q, too many decimal places", s)
+	}
+	if scale < 0 {
+		coef.Mul(coef, pow10(int32(-scale)))
+		scale = 0
+	}
+	return newDecimal(coef, int32(scale)), nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics if s is not a decimal number
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+func (d Decimal) coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// rescale returns the coefficient of d with scale decimal places, scale must be >= d.scale
+func (d Decimal) rescale(scale int32) *big.Int {
+	coef := new(big.Int).Set(d.coefficient())
+	if scale > d.scale {
+		coef.Mul(coef, pow10(scale-d.scale))
+	}
+	return coef
+}
+
+// align returns the coefficients of d and e with the same scale
+func align(d Decimal, e Decimal) (*big.Int, *big.Int, int32) {
+	scale := d.scale
+	if e.scale > scale {
+		scale = e.scale
+	}
+	return d.rescale(scale), e.rescale(scale), scale
+}
+
+// Add returns d + e
+func (d Decimal) Add(e Decimal) Decimal {
+	a, b, scale := align(d, e)
+	return newDecimal(a.Add(a, b), scale)
+}
+
+// Sub returns d - e
+func (d Decimal) Sub(e Decimal) Decimal {
+	a, b, scale := align(d, e)
+	return newDecimal(a.Sub(a, b), scale)
+}
+
+// Mul returns d * e, rounded half away from zero to 1000 decimal places if it has more
+func (d Decimal) Mul(e Decimal) Decimal {
+	product := newDecimal(new(big.Int).Mul(d.coefficient(), e.coefficient()), d.scale+e.scale)
+	return product.Round(maxDecimalExponent)
+}
+
+// Div returns d / e rounded half away from zero to places decimal places, at most 1000.
+// It panics if e is 0.
+func (d Decimal) Div(e Decimal, places int32) Decimal {
+	if e.IsZero() {
+		panic("p2pb2b: Decimal division by zero")
+	}
+	if places < 0 {
+		places = 0
+	}
+	if places > maxDecimalExponent {
+		places = maxDecimalExponent
+	}
+	// d / e = dc / ec * 10^(e.scale - d.scale)
+	num := new(big.Int).Set(d.coefficient())
+	den := new(big.Int).Set(e.coefficient())
+	if exp := int64(e.scale) - int64(d.scale) + int64(places); exp >= 0 {
+		num.Mul(num, pow10(int32(exp)))
+	} else {
+		den.Mul(den, pow10(int32(-exp)))
+	}
+	return newDecimal(quoRound(num, den), places)
+}
+
+// quoRound returns num / den rounded half away from zero
+func quoRound(num *big.Int, den *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	if twice.Cmp(new(big.Int).Abs(den)) >= 0 {
+		if num.Sign() == den.Sign() {
+			q.Add(q, bigOne)
+		} else {
+			q.Sub(q, bigOne)
+		}
+	}
+	return q
+}
+
+// Round returns d rounded half away from zero to places decimal places
+func (d Decimal) Round(places int32) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if d.scale <= places {
+		return d
+	}
+	return newDecimal(quoRound(d.coefficient(), pow10(d.scale-places)), places)
+}
+
+// Truncate returns d rounded toward zero to places decimal places
+func (d Decimal) Truncate(places int32) Decimal {
+	if places < 0 {
+		places = 0
+	}
+	if d.scale <= places {
+		return d
+	}
+	return newDecimal(new(big.Int).Quo(d.coefficient(), pow10(d.scale-places)), places)
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	return newDecimal(new(big.Int).Neg(d.coefficient()), d.scale)
+}
+
+// Abs returns |d|
+func (d Decimal) Abs() Decimal {
+	return newDecimal(new(big.Int).Abs(d.coefficient()), d.scale)
+}
+
+// Cmp returns -1, 0 or +1 if d is less than, equal to or greater than e
+func (d Decimal) Cmp(e Decimal) int {
+	a, b, _ := align(d, e)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and e are the same number, regardless of their decimal places
+func (d Decimal) Equal(e Decimal) bool {
+	return d.Cmp(e) == 0
+}
+
+// LessThan reports whether d < e
+func (d Decimal) LessThan(e Decimal) bool {
+	return d.Cmp(e) < 0
+}
+
+// GreaterThan reports whether d > e
+func (d Decimal) GreaterThan(e Decimal) bool {
+	return d.Cmp(e) > 0
+}
+
+// Sign returns -1, 0 or +1 for negative, zero or positive d
+func (d Decimal) Sign() int {
+	return d.coefficient().Sign()
+}
+
+// IsZero reports whether d is 0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Scale returns the number of decimal places of d
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Float64 returns the nearest float64 of d
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String returns d with all its decimal places, e.g. "0.10"
+func (d Decimal) String() string {
+	coef := d.coefficient()
+	digits := new(big.Int).Abs(coef).String()
+	sign := ""
+	if coef.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale == 0 {
+		return sign + digits
+	}
+	if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// MarshalJSON encodes d as JSON string
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON decodes d from a JSON string or number. null and the empty string are 0.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		*d = Decimal{}
+		return nil
+	}
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		if s == "" {
+			*d = Decimal{}
+			return nil
+		}
+	}
+	parsed, err := ParseDecimal(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalText encodes d like String, e.g. for YAML or map keys
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes d with ParseDecimal
+func (d *Decimal) UnmarshalText(b []byte) error {
+	parsed, err := ParseDecimal(string(b))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,141 @@
+package p2pb2b
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDecimal(t *testing.T) {
+	for input, expected := range map[string]string{
+		"0":                             "0",
+		"0.10":                          "0.10",
+		"-0.00012345":                   "-0.00012345",
+		"+12":                           "12",
+		"1.5e-8":                        "0.000000015",
+		"1.5E2":                         "150",
+		".5":                            "0.5",
+		"3469.740614926":                "3469.740614926",
+		"123456789012345678":            "123456789012345678",
+		"0.000000000000000000000000001": "0.000000000000000000000000001",
+	} {
+		d, err := ParseDecimal(input)
+		assert.Nil(t, err, input)
+		assert.Equal(t, expected, d.String(), input)
+	}
+	for _, input := range []string{"", "-", "abc", "1.2.3", "1e", "0x10", "1,5"} {
+		_, err := ParseDecimal(input)
+		assert.NotNil(t, err, input)
+	}
+}
+
+func TestParseDecimalLimitsExponent(t *testing.T) {
+	start := time.Now()
+	for _, input := range []string{"1e100000000", "1e-2147483000", "1e1001", "1e-1001", "0." + strings.Repeat("1", 1001)} {
+		_, err := ParseDecimal(input)
+		assert.NotNil(t, err, input)
+	}
+	d, err := ParseDecimal("1e1000")
+	assert.Nil(t, err)
+	assert.Equal(t, 1001, len(d.String()))
+	d, err = ParseDecimal("1e-1000")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1000), d.Scale())
+
+	assert.Panics(t, func() { NewDecimal(1, -200000000) })
+	assert.Panics(t, func() { NewDecimal(1, math.MinInt32) })
+	assert.Panics(t, func() { NewDecimal(1, 1001) })
+	assert.Equal(t, int32(1000), NewDecimal(1, 1000).Scale())
+	assert.Equal(t, 1001, len(NewDecimal(1, -1000).String()))
+
+	// products are rounded to 1000 decimal places instead of growing without bound
+	tiny := MustParseDecimal("1e-1000")
+	product := tiny
+	for i := 0; i < 8; i++ {
+		product = product.Mul(tiny)
+	}
+	assert.Equal(t, int32(1000), product.Scale())
+	assert.True(t, product.IsZero())
+	assert.True(t, MustParseDecimal("1e-500").Mul(MustParseDecimal("5e-501")).Equal(MustParseDecimal("1e-1000")))
+	assert.Equal(t, int32(1000), NewDecimalFromInt(1).Div(NewDecimalFromInt(3), 1<<30).Scale())
+
+	var ticker TickerResp
+	err = json.Unmarshal([]byte(`{"result":{"last":"1e100000000"}}`), &ticker)
+	assert.NotNil(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	a := MustParseDecimal("0.1")
+	b := MustParseDecimal("0.2")
+	assert.Equal(t, "0.3", a.Add(b).String())
+	assert.True(t, a.Add(b).Equal(MustParseDecimal("0.30")))
+	assert.Equal(t, "-0.1", a.Sub(b).String())
+	assert.Equal(t, "0.02", a.Mul(b).String())
+	assert.Equal(t, "0.5", a.Div(b, 8).Round(1).String())
+	assert.Equal(t, "0.33333333", NewDecimalFromInt(1).Div(NewDecimalFromInt(3), 8).String())
+	assert.Equal(t, "0.67", NewDecimalFromInt(2).Div(NewDecimalFromInt(3), 2).String())
+	assert.Equal(t, "-0.67", NewDecimalFromInt(-2).Div(NewDecimalFromInt(3), 2).String())
+
+	assert.Equal(t, "0.13", MustParseDecimal("0.125").Round(2).String())
+	assert.Equal(t, "-0.13", MustParseDecimal("-0.125").Round(2).String())
+	assert.Equal(t, "0.12", MustParseDecimal("0.125").Truncate(2).String())
+	assert.Equal(t, "1.5", NewDecimal(15, 1).String())
+	assert.Equal(t, "0.1", NewDecimalFromFloat(0.1).String())
+	assert.Equal(t, "0.1", MustParseDecimal("-0.1").Abs().String())
+	assert.Equal(t, "-0.1", a.Neg().String())
+
+	// summing many small amounts does not drift like float64
+	sum := Decimal{}
+	for i := 0; i < 1000; i++ {
+		sum = sum.Add(MustParseDecimal("0.00000001"))
+	}
+	assert.Equal(t, "0.00001000", sum.String())
+
+	assert.Panics(t, func() { a.Div(Decimal{}, 2) })
+}
+
+func TestDecimalCompare(t *testing.T) {
+	a := MustParseDecimal("0.021486")
+	b := MustParseDecimal("0.0215")
+	assert.Equal(t, -1, a.Cmp(b))
+	assert.True(t, a.LessThan(b))
+	assert.True(t, b.GreaterThan(a))
+	assert.True(t, MustParseDecimal("1.0").Equal(NewDecimalFromInt(1)))
+	assert.True(t, Decimal{}.IsZero())
+	assert.True(t, MustParseDecimal("0.000").IsZero())
+	assert.Equal(t, -1, MustParseDecimal("-1").Sign())
+	assert.Equal(t, int32(6), a.Scale())
+	assert.Equal(t, 0.021486, a.Float64())
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var v struct {
+		String Decimal `json:"string"`
+		Number Decimal `json:"number"`
+		Null   Decimal `json:"null"`
+		Empty  Decimal `json:"empty"`
+	}
+	err := json.Unmarshal([]byte(`{"string":"0.00000001","number":3469.740614926,"null":null,"empty":""}`), &v)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.00000001", v.String.String())
+	assert.Equal(t, "3469.740614926", v.Number.String())
+	assert.True(t, v.Null.IsZero())
+	assert.True(t, v.Empty.IsZero())
+
+	asJSON, err := json.Marshal(v)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"string":"0.00000001","number":"3469.740614926","null":"0","empty":"0"}`, string(asJSON))
+
+	// request amounts are sent exactly as given
+	asJSON, err = json.Marshal(&CreateOrderRequest{Market: "ETH_BTC", Side: "buy", Amount: MustParseDecimal("0.10"), Price: MustParseDecimal("0.021486")})
+	assert.Nil(t, err)
+	assert.Contains(t, string(asJSON), `"amount":"0.10","price":"0.021486"`)
+
+	assert.NotNil(t, json.Unmarshal([]byte(`"abc"`), &v.String))
+	assert.NotNil(t, json.Unmarshal([]byte(`true`), &v.String))
+}
@@ -1,10 +1,9 @@
 package p2pb2b
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"net/url"
 )
 
 type DepthResultResp struct {
@@ -15,13 +14,23 @@ type DepthResultResp struct {
 }
 
 type DepthResultResult struct {
-	Asks []Float64Pair `json:"asks"`
-	Bids []Float64Pair `json:"bids"`
+	Asks []DecimalPair `json:"asks"`
+	Bids []DecimalPair `json:"bids"`
 }
 
-type Float64Pair [2]float64
+// DecimalPair is a price level of the depth result, price and amount
+type DecimalPair [2]Decimal
+
+// Float64Pair is the former name of DecimalPair.
+//
+// Deprecated: Use DecimalPair. Price and amount are Decimal values instead of float64.
+type Float64Pair = DecimalPair
 
 func (c *client) GetDepthResult(market string, limit int64) (*DepthResultResp, error) {
+	return c.GetDepthResultCtx(context.Background(), market, limit)
+}
+
+func (c *client) GetDepthResultCtx(ctx context.Context, market string, limit int64) (*DepthResultResp, error) {
 	if market == "" {
 		return nil, fmt.Errorf("parameter market must not be empty")
 	}
@@ -29,45 +38,11 @@ func (c *client) GetDepthResult(market string, limit int64) (*DepthResultResp, e
 		return nil, fmt.Errorf("parameter limit must not be <= 0")
 	}
 
-	url := fmt.Sprintf("%s/public/depth/result?market=%s&limit=%d", c.url, market, limit)
-
-	resp, err := c.sendGet(url, nil)
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	query := fmt.Sprintf("market=%s&limit=%d", url.QueryEscape(market), limit)
 
 	var result DepthResultResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, depthResultEndpoint, query, nil, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
-
-func (pair *Float64Pair) UnmarshalJSON(b []byte) error {
-	tmp := []json.Number{}
-	if err := json.Unmarshal(b, &tmp); err != nil {
-		return err
-	}
-
-	left, err := tmp[0].Float64()
-	if err != nil {
-		return err
-	}
-	right, err := tmp[1].Float64()
-	if err != nil {
-		return err
-	}
-	*pair = Float64Pair{left, right}
-
-	return nil
-}
@@ -71,14 +71,14 @@ func TestGetDepthResult(t *testing.T) {
 	assert.Equal(t, "", resp.Message)
 
 	assert.Equal(t, 2, len(resp.Result.Asks))
-	assert.Equal(t, 0.021486, resp.Result.Asks[0][0])
-	assert.Equal(t, 0.654, resp.Result.Asks[0][1])
-	assert.Equal(t, 0.021488, resp.Result.Asks[1][0])
-	assert.Equal(t, 0.836, resp.Result.Asks[1][1])
-	assert.Equal(t, 0.021462, resp.Result.Bids[0][0])
-	assert.Equal(t, 0.665, resp.Result.Bids[0][1])
-	assert.Equal(t, 0.021455, resp.Result.Bids[1][0])
-	assert.Equal(t, 0.032, resp.Result.Bids[1][1])
+	assert.Equal(t, "0.021486", resp.Result.Asks[0][0].String())
+	assert.Equal(t, "0.654", resp.Result.Asks[0][1].String())
+	assert.Equal(t, "0.021488", resp.Result.Asks[1][0].String())
+	assert.Equal(t, "0.836", resp.Result.Asks[1][1].String())
+	assert.Equal(t, "0.021462", resp.Result.Bids[0][0].String())
+	assert.Equal(t, "0.665", resp.Result.Bids[0][1].String())
+	assert.Equal(t, "0.021455", resp.Result.Bids[1][0].String())
+	assert.Equal(t, "0.032", resp.Result.Bids[1][1].String())
 }
 
 func TestGetDepthResultNegative(t *testing.T) {
@@ -0,0 +1,180 @@
+package p2pb2b
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DryRunRequest is a trading request built and signed in dry-run mode, which was not sent
+type DryRunRequest struct {
+	// Endpoint is the name of the endpoint, /order/new or /order/cancel
+	Endpoint string
+	// Request is the signed HTTP request, including the X-TXC headers
+	Request *http.Request
+	// Body is the JSON body of the request
+	Body []byte
+}
+
+// DryRunHook receives the trading requests of a client in dry-run mode. It is called
+// synchronously and must not block.
+type DryRunHook func(request DryRunRequest)
+
+// dryRun is the innermost Doer of a client in dry-run mode. It answers CreateOrder and
+// CancelOrder requests with synthetic responses and passes all other requests to next.
+// Orders created in dry-run mode get negative IDs, so they cannot be confused with real ones.
+type dryRun struct {
+	next   Doer
+	hook   DryRunHook
+	logger func() Logger
+	now    func() time.Time
+
+	mu     sync.Mutex
+	lastID int64
+	orders map[int64]dryRunOrder
+}
+
+// dryRunOrder is the result of a synthetic order, with amounts kept as sent
+type dryRunOrder struct {
+	Amount    json.RawMessage `json:"amount"`
+	DealFee   string          `json:"dealFee"`
+	DealMoney string          `json:"dealMoney"`
+	DealStock string          `json:"dealStock"`
+	Left      json.RawMessage `json:"left"`
+	MakerFee  string          `json:"makerFee"`
+	Market    string          `json:"market"`
+	OrderID   int64           `json:"orderId"`
+	Price     json.RawMessage `json:"price"`
+	Side      string          `json:"side"`
+	TakerFee  string          `json:"takerFee"`
+	Timestamp float64         `json:"timestamp"`
+	Type      string          `json:"type"`
+}
+
+// dryRunBody is the part of trading request bodies a synthetic order is built from
+type dryRunBody struct {
+	Market  string          `json:"market"`
+	Side    string          `json:"side"`
+	Amount  json.RawMessage `json:"amount"`
+	Price   json.RawMessage `json:"price"`
+	OrderID int64           `json:"orderId"`
+}
+
+// intercepts reports whether request is answered by d instead of being sent, false if d is nil
+func (d *dryRun) intercepts(request *http.Request) bool {
+	if d == nil {
+		return false
+	}
+	endpoint := EndpointName(request)
+	return endpoint == createOrderEndpoint.path || endpoint == cancelOrderEndpoint.path
+}
+
+func (d *dryRun) Do(request *http.Request) (*http.Response, error) {
+	if !d.intercepts(request) {
+		return d.next.Do(request)
+	}
+	endpoint := EndpointName(request)
+	var body []byte
+	if request.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading dry-run request, %v", err)
+		}
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	d.logger().Info("p2pb2b dry-run request not sent",
+		"endpoint", endpoint,
+		"requestHeaders", redactHeaders(request.Header),
+		"body", string(body))
+	if d.hook != nil {
+		d.hook(DryRunRequest{Endpoint: endpoint, Request: request, Body: body})
+	}
+
+	var parsed dryRunBody
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("error parsing dry-run request, %v", err)
+	}
+	var order dryRunOrder
+	if endpoint == createOrderEndpoint.path {
+		order = d.create(parsed)
+	} else {
+		order = d.cancel(parsed)
+	}
+	respBody, err := json.Marshal(map[string]interface{}{
+		"success": true,
+		"message": "",
+		"result":  order,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       request,
+	}, nil
+}
+
+// create returns a new open order with a negative ID
+func (d *dryRun) create(body dryRunBody) dryRunOrder {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastID--
+	order := dryRunOrder{
+		Amount:    body.Amount,
+		DealFee:   "0",
+		DealMoney: "0",
+		DealStock: "0",
+		Left:      body.Amount,
+		MakerFee:  "0",
+		Market:    body.Market,
+		OrderID:   d.lastID,
+		Price:     body.Price,
+		Side:      body.Side,
+		TakerFee:  "0",
+		Timestamp: float64(d.now().UnixNano()) / 1e9,
+		Type:      "limit",
+	}
+	if d.orders == nil {
+		d.orders = map[int64]dryRunOrder{}
+	}
+	d.orders[order.OrderID] = order
+	return order
+}
+
+// cancel returns the canceled dry-run order, or an order with the requested ID and market
+func (d *dryRun) cancel(body dryRunBody) dryRunOrder {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if order, ok := d.orders[body.OrderID]; ok && order.Market == body.Market {
+		delete(d.orders, body.OrderID)
+		return order
+	}
+	zero := json.RawMessage(`"0"`)
+	return dryRunOrder{
+		Amount:    zero,
+		DealFee:   "0",
+		DealMoney: "0",
+		DealStock: "0",
+		Left:      zero,
+		MakerFee:  "0",
+		Market:    body.Market,
+		OrderID:   body.OrderID,
+		Price:     zero,
+		TakerFee:  "0",
+		Timestamp: float64(d.now().UnixNano()) / 1e9,
+		Type:      "limit",
+	}
+}
@@ -0,0 +1,126 @@
+package p2pb2b
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDryRun(t *testing.T) {
+	var trading, other int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/order/new", "/order/cancel":
+			atomic.AddInt32(&trading, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/account/balances":
+			atomic.AddInt32(&other, 1)
+			w.Write([]byte(`{"success":true,"message":"","result":{"ETH":{"available":"0.1","freeze":"0"}}}`))
+		default:
+			atomic.AddInt32(&other, 1)
+			w.Write([]byte(`{"success":true,"message":"","result":[]}`))
+		}
+	}))
+	defer ts.Close()
+
+	var requests []DryRunRequest
+	var middlewareCalls int32
+	client, err := newClientWithURL(ts.URL, "key", "secret",
+		WithDryRun(func(request DryRunRequest) {
+			requests = append(requests, request)
+		}),
+		WithMiddleware(func(next Doer) Doer {
+			return DoerFunc(func(request *http.Request) (*http.Response, error) {
+				atomic.AddInt32(&middlewareCalls, 1)
+				return next.Do(request)
+			})
+		}))
+	assert.Nil(t, err)
+
+	created, err := client.CreateOrder(&CreateOrderRequest{Market: "ETH_BTC", Side: "buy", Amount: MustParseDecimal("1.5"), Price: MustParseDecimal("0.021")})
+	assert.Nil(t, err)
+	assert.True(t, created.Success)
+	assert.Equal(t, int64(-1), created.Result.OrderID)
+	assert.Equal(t, "ETH_BTC", created.Result.Market)
+	assert.Equal(t, "buy", created.Result.Side)
+	assert.Equal(t, "1.5", created.Result.Amount.String())
+	assert.Equal(t, "1.5", created.Result.Left.String())
+	assert.Equal(t, "0.021", created.Result.Price.String())
+
+	// the hook gets the request signed as if it was sent
+	if assert.Len(t, requests, 1) {
+		request := requests[0]
+		assert.Equal(t, "/order/new", request.Endpoint)
+		assert.Equal(t, "key", request.Request.Header.Get(HeaderXTxcAPIKey))
+		signer, _ := NewHMACSigner(HMACSHA256, "secret")
+		headers, _ := signer.Sign(request.Body)
+		assert.Equal(t, headers[HeaderXTxcSignature], request.Request.Header.Get(HeaderXTxcSignature))
+		assert.Contains(t, string(request.Body), `"request":"/order/new"`)
+	}
+
+	canceled, err := client.CancelOrder(&CancelOrderRequest{Market: "ETH_BTC", OrderID: created.Result.OrderID})
+	assert.Nil(t, err)
+	assert.Equal(t, created.Result, canceled.Result)
+
+	canceled, err = client.CancelOrder(&CancelOrderRequest{Market: "ETH_BTC", OrderID: 42})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(42), canceled.Result.OrderID)
+	assert.Equal(t, "0", canceled.Result.Amount.String())
+
+	second, err := client.CreateOrder(&CreateOrderRequest{Market: "ETH_BTC", Side: "sell", Amount: MustParseDecimal("1"), Price: MustParseDecimal("0.03")})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-2), second.Result.OrderID)
+
+	// public and read-only account requests are sent
+	_, err = client.GetMarkets()
+	assert.Nil(t, err)
+	balances, err := client.PostBalances(&AccountBalancesRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, "0.1", balances.Result["ETH"].Available.String())
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&trading))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&other))
+	assert.Equal(t, int32(6), atomic.LoadInt32(&middlewareCalls))
+	assert.Len(t, requests, 4)
+}
+
+func TestDryRunSkipsRateLimiterAndMetrics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"message":"","result":{}}`))
+	}))
+	defer ts.Close()
+
+	metrics := NewMetrics()
+	client, err := newClientWithURL(ts.URL, "key", "secret",
+		WithDryRun(nil),
+		WithMetrics(metrics),
+		WithRateLimiter(RateLimiterConfig{
+			Public:   RateLimit{Rate: 1, Burst: 1},
+			Private:  RateLimit{Rate: 1, Burst: 1},
+			FailFast: true,
+		}))
+	assert.Nil(t, err)
+
+	// synthetic trading requests do not use up the private token bucket
+	for i := 0; i < 5; i++ {
+		_, err = client.CreateOrder(&CreateOrderRequest{Market: "ETH_BTC", Side: "buy", Amount: MustParseDecimal("1"), Price: MustParseDecimal("0.02")})
+		assert.Nil(t, err)
+	}
+	_, err = client.CancelOrder(&CancelOrderRequest{Market: "ETH_BTC", OrderID: -1})
+	assert.Nil(t, err)
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	assert.Nil(t, err)
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	assert.True(t, errors.Is(err, ErrRateLimitExceeded))
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	text := rec.Body.String()
+	assert.NotContains(t, text, `endpoint="/order/new"`)
+	assert.NotContains(t, text, `endpoint="/order/cancel"`)
+	assert.Contains(t, text, `p2pb2b_requests_total{endpoint="/account/balances",status="200"} 1`)
+}
@@ -0,0 +1,184 @@
+package p2pb2b
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+)
+
+// endpoint describes an API endpoint
+type endpoint struct {
+	// path relative to the base URL
+	path string
+	// private endpoints are signed POST requests, public ones are GET requests
+	private bool
+	// idempotent endpoints may be retried
+	idempotent bool
+}
+
+var (
+	balancesEndpoint        = endpoint{path: "/account/balances", private: true, idempotent: true}
+	currencyBalanceEndpoint = endpoint{path: "/account/balance", private: true, idempotent: true}
+	createOrderEndpoint     = endpoint{path: "/order/new", private: true}
+	cancelOrderEndpoint     = endpoint{path: "/order/cancel", private: true}
+	unexecutedEndpoint      = endpoint{path: "/orders", private: true, idempotent: true}
+	executedEndpoint        = endpoint{path: "/account/order_history", private: true, idempotent: true}
+	dealsEndpoint           = endpoint{path: "/account/order", private: true, idempotent: true}
+	marketsEndpoint         = endpoint{path: "/public/markets", idempotent: true}
+	tickersEndpoint         = endpoint{path: "/public/tickers", idempotent: true}
+	tickerEndpoint          = endpoint{path: "/public/ticker", idempotent: true}
+	orderBookEndpoint       = endpoint{path: "/public/book", idempotent: true}
+	historyEndpoint         = endpoint{path: "/public/history", idempotent: true}
+	depthResultEndpoint     = endpoint{path: "/public/depth/result", idempotent: true}
+	productsEndpoint        = endpoint{path: "/public/products", idempotent: true}
+	symbolsEndpoint         = endpoint{path: "/public/symbols", idempotent: true}
+)
+
+// signedRequest is implemented by all request structs embedding Request
+type signedRequest interface {
+	baseRequest() *Request
+}
+
+func (r *Request) baseRequest() *Request {
+	return r
+}
+
+// copyRequest returns a shallow copy of request, so filling Request and Nonce leaves
+// the struct of the caller untouched
+func copyRequest(request signedRequest) (signedRequest, error) {
+	v := reflect.ValueOf(request)
+	if request == nil || v.IsNil() {
+		return nil, fmt.Errorf("parameter request must not be nil")
+	}
+	cp := reflect.New(v.Elem().Type())
+	cp.Elem().Set(v.Elem())
+	return cp.Interface().(signedRequest), nil
+}
+
+// call is the pipeline of all endpoints. It sends a GET request with query to a public
+// endpoint, or request signed as JSON body to a private endpoint, checks the status and
+// success of the response and decodes it into result. The response body is always closed.
+func (c *client) call(ctx context.Context, e endpoint, query string, request signedRequest, result interface{}) error {
+	if e.private {
+		if _, err := copyRequest(request); err != nil {
+			return err
+		}
+	}
+	ctx = contextWithEndpointName(ctx, e.path)
+
+	send := func() (*response, error) {
+		return c.withRetry(ctx, e.idempotent, c.sendAttempt(ctx, e, query, request))
+	}
+	var resp *response
+	var err error
+	if c.coalescer != nil && !e.private {
+		resp, err = c.coalescer.do(ctx, c.url+e.path+"?"+query, c.maxResponseSize, send)
+	} else {
+		resp, err = send()
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	err = checkHTTPStatus(*resp, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	body := &captureBuffer{limit: maxErrorBodySize}
+	err = c.decode(*resp, result, body)
+	if err != nil {
+		return err
+	}
+	if receiver, ok := result.(metaReceiver); ok {
+		receiver.setMeta(newResponseMeta(*resp, body))
+	}
+	// a coalesced response is observed once, by the caller which sent the request
+	if c.clock != nil && !resp.sent.IsZero() && !resp.coalesced {
+		if serverTime := currentTime(result); serverTime > 0 {
+			c.clock.Observe(TimestampToTime(serverTime), resp.sent, resp.received)
+		}
+	}
+	return nil
+}
+
+// sendAttempt returns a function sending one attempt of a call through the failover base URLs
+func (c *client) sendAttempt(ctx context.Context, e endpoint, query string, request signedRequest) func() (*response, error) {
+	return func() (*response, error) {
+		return c.sendFailover(ctx, e.private, func(base string) (*response, error) {
+			endpointURL := base + e.path
+			if query != "" {
+				endpointURL += "?" + query
+			}
+			if !e.private {
+				return c.sendGet(ctx, endpointURL, nil)
+			}
+			payload, err := copyRequest(request)
+			if err != nil {
+				return nil, err
+			}
+			if err := c.fillRequest(payload.baseRequest(), endpointURL); err != nil {
+				return nil, err
+			}
+			asJSON, err := json.Marshal(payload)
+			if err != nil {
+				return nil, err
+			}
+			return c.sendPost(ctx, endpointURL, nil, bytes.NewReader(asJSON))
+		})
+	}
+}
+
+// statusReporter is implemented by all response structs
+type statusReporter interface {
+	succeeded() bool
+}
+
+func (r *Response) succeeded() bool {
+	return r.Success
+}
+
+func (r *CreateOrderResp) succeeded() bool {
+	return r.Success
+}
+
+func (r *CancelOrderResp) succeeded() bool {
+	return r.Success
+}
+
+func (r *QueryUnexecutedResp) succeeded() bool {
+	return r.Success
+}
+
+// decode decodes the body of resp into result as a stream. The start of the body is
+// kept in capture, to return an APIError if the response reports success=false.
+func (c *client) decode(resp response, result interface{}, capture *captureBuffer) error {
+	body := limitedBody(resp.Body, c.maxResponseSize)
+	decodeErr := json.NewDecoder(io.TeeReader(body, capture)).Decode(result)
+	if errors.Is(decodeErr, ErrResponseTooLarge) {
+		return fmt.Errorf("error reading response of %s, %w", resp.Endpoint, decodeErr)
+	}
+
+	status, ok := result.(statusReporter)
+	if decodeErr == nil && (!ok || status.succeeded()) {
+		return nil
+	}
+	// failed responses have a different schema, so the body is checked for success=false
+	if _, err := io.Copy(capture, body); err == nil && !capture.truncated {
+		if err := checkSuccess(resp, capture.buf); err != nil {
+			var apiErr *APIError
+			if errors.As(err, &apiErr) || decodeErr == nil {
+				return err
+			}
+		}
+	}
+	if decodeErr != nil {
+		return decodeErr
+	}
+	return newAPIError(resp, capture.buf)
+}
@@ -0,0 +1,121 @@
+package p2pb2b
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type trackingBody struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+// stubTransport answers every request with status and body, tracking the response bodies
+type stubTransport struct {
+	mu     sync.Mutex
+	status int
+	body   string
+	bodies []*trackingBody
+}
+
+func (s *stubTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body := &trackingBody{Reader: bytes.NewReader([]byte(s.body))}
+	s.bodies = append(s.bodies, body)
+	return &http.Response{
+		StatusCode: s.status,
+		Status:     http.StatusText(s.status),
+		Header:     http.Header{},
+		Body:       body,
+		Request:    r,
+	}, nil
+}
+
+func TestCallClosesResponseBody(t *testing.T) {
+	for _, stub := range []*stubTransport{
+		{status: http.StatusOK, body: `{"success":true,"message":"","result":[]}`},
+		{status: http.StatusOK, body: `{"success":false,"message":"market not found","result":[]}`},
+		{status: http.StatusOK, body: `{"success":true,"message":"","result":"no list"}`},
+		{status: http.StatusBadGateway, body: `bad gateway`},
+	} {
+		client, err := newClientWithURL("http://p2pb2b.test", "key", "secret", WithTransport(stub))
+		assert.Nil(t, err)
+
+		client.GetMarkets()
+		client.PostBalances(&AccountBalancesRequest{})
+
+		assert.Equal(t, 2, len(stub.bodies))
+		for _, body := range stub.bodies {
+			assert.True(t, body.closed, "body of %d %s not closed", stub.status, stub.body)
+		}
+	}
+}
+
+func TestCallDecodesResponse(t *testing.T) {
+	stub := &stubTransport{status: http.StatusOK, body: `{"success":true,"message":"","result":{"ETH":{"available":"0.1","freeze":"0.4"}}}`}
+	client, err := newClientWithURL("http://p2pb2b.test", "key", "secret", WithTransport(stub))
+	assert.Nil(t, err)
+
+	resp, err := client.PostBalances(&AccountBalancesRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, "0.1", resp.Result["ETH"].Available.String())
+	assert.Equal(t, "0.4", resp.Result["ETH"].Freeze.String())
+}
+
+func TestCallRejectsNilRequest(t *testing.T) {
+	stub := &stubTransport{status: http.StatusOK, body: `{}`}
+	client, err := newClientWithURL("http://p2pb2b.test", "key", "secret", WithTransport(stub))
+	assert.Nil(t, err)
+
+	_, err = client.QueryExecuted(nil)
+	assert.NotNil(t, err)
+	_, err = client.PostCurrencyBalance(nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(stub.bodies))
+}
+
+func TestCopyRequest(t *testing.T) {
+	request := &CancelOrderRequest{Market: "ETH_BTC", OrderID: 25749}
+	cp, err := copyRequest(request)
+	assert.Nil(t, err)
+
+	cp.baseRequest().Nonce = "1"
+	assert.Equal(t, "", request.Nonce)
+	assert.Equal(t, &CancelOrderRequest{Request: Request{Nonce: "1"}, Market: "ETH_BTC", OrderID: 25749}, cp)
+
+	_, err = copyRequest(nil)
+	assert.NotNil(t, err)
+	var nilRequest *CancelOrderRequest
+	_, err = copyRequest(nilRequest)
+	assert.NotNil(t, err)
+}
+
+func TestQueryParametersAreEscaped(t *testing.T) {
+	var requested string
+	client, err := newClientWithURL("http://p2pb2b.test", "key", "secret", WithMiddleware(func(next Doer) Doer {
+		return DoerFunc(func(r *http.Request) (*http.Response, error) {
+			requested = r.URL.String()
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"success":true,"message":"","result":{}}`))),
+			}, nil
+		})
+	}))
+	assert.Nil(t, err)
+
+	_, err = client.GetTicker("ETH_BTC&side=buy")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://p2pb2b.test/public/ticker?market=ETH_BTC%26side%3Dbuy", requested)
+}
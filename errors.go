@@ -0,0 +1,198 @@
+package p2pb2b
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrAuth classifies API errors caused by missing or invalid credentials, signatures or nonces
+	ErrAuth = errors.New("authentication failed")
+	// ErrRateLimited classifies API errors caused by exceeding the request limits of the exchange
+	ErrRateLimited = errors.New("rate limited")
+	// ErrInsufficientFunds classifies API errors caused by a balance too small for an order
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrOrderNotFound classifies API errors caused by an unknown order
+	ErrOrderNotFound = errors.New("order not found")
+)
+
+// APIError is returned when the exchange answers with an unexpected HTTP status or
+// with success=false. Its kind can be checked with errors.Is against ErrAuth,
+// ErrRateLimited, ErrInsufficientFunds and ErrOrderNotFound, or with the Is* helpers.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Code is the errorCode of the response, 0 if not present
+	Code int
+	// Message is the error message of the exchange
+	Message string
+	// Endpoint is the path of the called endpoint, e.g. /api/v1/order/new
+	Endpoint string
+	// Body is the raw response body
+	Body []byte
+	// Header are the HTTP headers of the response
+	Header http.Header
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("p2pb2b api error on %s: http status %d", e.Endpoint, e.StatusCode)
+	if e.Code != 0 {
+		msg = fmt.Sprintf("%s, code %d", msg, e.Code)
+	}
+	if e.Message != "" {
+		msg = fmt.Sprintf("%s, %s", msg, e.Message)
+	}
+	return msg
+}
+
+// Is reports whether the error is of the kind described by target
+func (e *APIError) Is(target error) bool {
+	message := strings.ToLower(e.Message)
+	switch target {
+	case ErrAuth:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden ||
+			containsAny(message, "key not provided", "signature", "unauthorized", "authentication",
+				"apikey", "api key", "nonce", "not authorized")
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests ||
+			containsAny(message, "too many requests", "rate limit")
+	case ErrInsufficientFunds:
+		return containsAny(message, "insufficient", "balance not enough", "not enough balance")
+	case ErrOrderNotFound:
+		return containsAny(message, "order not found", "order was not found", "order does not exist",
+			"order not exist")
+	}
+	return false
+}
+
+// IsAuthError reports whether err is an APIError caused by missing or invalid credentials
+func IsAuthError(err error) bool {
+	return errors.Is(err, ErrAuth)
+}
+
+// IsRateLimited reports whether err is an APIError caused by exceeding request limits
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsInsufficientFunds reports whether err is an APIError caused by a too small balance
+func IsInsufficientFunds(err error) bool {
+	return errors.Is(err, ErrInsufficientFunds)
+}
+
+// IsOrderNotFound reports whether err is an APIError caused by an unknown order
+func IsOrderNotFound(err error) bool {
+	return errors.Is(err, ErrOrderNotFound)
+}
+
+// ErrorClass returns a short class of err for metrics and logs: auth, rate_limited,
+// insufficient_funds, order_not_found, api for other APIErrors and an empty string
+// for errors which are no APIError.
+func ErrorClass(err error) string {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return ""
+	}
+	switch {
+	case apiErr.Is(ErrAuth):
+		return "auth"
+	case apiErr.Is(ErrRateLimited):
+		return "rate_limited"
+	case apiErr.Is(ErrInsufficientFunds):
+		return "insufficient_funds"
+	case apiErr.Is(ErrOrderNotFound):
+		return "order_not_found"
+	}
+	return "api"
+}
+
+func containsAny(s string, substrs ...string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorResponse is the part of a response describing a failure
+type errorResponse struct {
+	Success   *bool           `json:"success"`
+	Message   json.RawMessage `json:"message"`
+	ErrorCode int             `json:"errorCode"`
+}
+
+// newAPIError creates the APIError of resp and reports it to the metrics of the response
+func newAPIError(resp response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Endpoint:   resp.Endpoint,
+		Body:       body,
+		Header:     resp.Header,
+	}
+	var errResp errorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil {
+		apiErr.Code = errResp.ErrorCode
+		apiErr.Message = flattenMessage(errResp.Message)
+	}
+	if resp.metrics != nil {
+		resp.metrics.ObserveAPIError(resp.endpointName, ErrorClass(apiErr))
+	}
+	return apiErr
+}
+
+// flattenMessage converts a message of the exchange into a single string. Messages
+// are either plain strings or nested arrays and objects of strings, e.g. [["Key not provided."]].
+func flattenMessage(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return string(raw)
+	}
+	var parts []string
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch t := v.(type) {
+		case string:
+			if t != "" {
+				parts = append(parts, t)
+			}
+		case []interface{}:
+			for _, e := range t {
+				collect(e)
+			}
+		case map[string]interface{}:
+			keys := make([]string, 0, len(t))
+			for k := range t {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				collect(t[k])
+			}
+		case nil:
+		default:
+			parts = append(parts, fmt.Sprint(t))
+		}
+	}
+	collect(value)
+	return strings.Join(parts, "; ")
+}
+
+// checkSuccess returns an APIError if the response body reports success=false
+func checkSuccess(resp response, body []byte) error {
+	var errResp errorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		return err
+	}
+	if errResp.Success != nil && !*errResp.Success {
+		return newAPIError(resp, body)
+	}
+	return nil
+}
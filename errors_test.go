@@ -0,0 +1,79 @@
+package p2pb2b
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIErrorOnHTTPStatus(t *testing.T) {
+	body := `{"success":false,"message":[["Key not provided."]],"result":[]}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL+"/api/v1", uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	resp, err := client.PostBalances(&AccountBalancesRequest{})
+	assert.Nil(t, resp)
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	assert.Equal(t, "Key not provided.", apiErr.Message)
+	assert.Equal(t, "/api/v1/account/balances", apiErr.Endpoint)
+	assert.Equal(t, body, string(apiErr.Body))
+	assert.True(t, IsAuthError(err))
+	assert.False(t, IsRateLimited(err))
+	assert.False(t, IsInsufficientFunds(err))
+	assert.False(t, IsOrderNotFound(err))
+}
+
+func TestAPIErrorOnSuccessFalse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":false,"errorCode":3080,"message":"Balance not enough","result":[]}`))
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd")
+	if err != nil {
+		t.Error(err.Error())
+	}
+	resp, err := client.CreateOrder(&CreateOrderRequest{Market: "ETH_BTC", Side: "buy", Amount: NewDecimalFromInt(1), Price: NewDecimalFromInt(1)})
+	assert.Nil(t, resp)
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusOK, apiErr.StatusCode)
+	assert.Equal(t, 3080, apiErr.Code)
+	assert.Equal(t, "Balance not enough", apiErr.Message)
+	assert.True(t, IsInsufficientFunds(err))
+	assert.True(t, errors.Is(err, ErrInsufficientFunds))
+	assert.False(t, IsAuthError(err))
+}
+
+func TestAPIErrorClassification(t *testing.T) {
+	assert.True(t, IsRateLimited(&APIError{StatusCode: http.StatusTooManyRequests}))
+	assert.True(t, IsRateLimited(&APIError{StatusCode: http.StatusOK, Message: "Too many requests"}))
+	assert.True(t, IsAuthError(&APIError{StatusCode: http.StatusUnauthorized}))
+	assert.True(t, IsAuthError(&APIError{StatusCode: http.StatusOK, Message: "Invalid signature"}))
+	assert.True(t, IsOrderNotFound(&APIError{StatusCode: http.StatusOK, Message: "Order not found"}))
+	assert.False(t, IsOrderNotFound(errors.New("order not found")))
+	assert.False(t, IsAuthError(nil))
+}
+
+func TestFlattenMessage(t *testing.T) {
+	assert.Equal(t, "", flattenMessage(nil))
+	assert.Equal(t, "plain", flattenMessage([]byte(`"plain"`)))
+	assert.Equal(t, "a; b", flattenMessage([]byte(`[["a"],["b"]]`)))
+	assert.Equal(t, "amount invalid; price invalid", flattenMessage([]byte(`{"price":["price invalid"],"amount":["amount invalid"]}`)))
+}
@@ -0,0 +1,165 @@
+package p2pb2b
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FailoverConfig configures failover across several API base URLs
+type FailoverConfig struct {
+	// BaseURLs are the API base URLs in order of preference, e.g. https://api.p2pb2b.io/api/v1
+	BaseURLs []string
+	// Cooldown is how long a base URL is considered unhealthy after a failed request,
+	// defaults to 30 seconds
+	Cooldown time.Duration
+}
+
+// endpointPool tracks the health of the base URLs of a client. A request to a base URL
+// fails on transport errors and 5xx responses, which marks it unhealthy for the cooldown.
+type endpointPool struct {
+	mu       sync.Mutex
+	urls     []*baseURL
+	sticky   *baseURL
+	cooldown time.Duration
+	now      func() time.Time
+}
+
+type baseURL struct {
+	url       string
+	downUntil time.Time
+}
+
+func newEndpointPool(config FailoverConfig) (*endpointPool, error) {
+	if len(config.BaseURLs) == 0 {
+		return nil, fmt.Errorf("failover needs at least one base url")
+	}
+	if config.Cooldown < 0 {
+		return nil, fmt.Errorf("failover cooldown must not be < 0")
+	}
+	pool := &endpointPool{cooldown: config.Cooldown, now: time.Now}
+	if pool.cooldown == 0 {
+		pool.cooldown = 30 * time.Second
+	}
+	for _, u := range config.BaseURLs {
+		normalized, err := parseBaseURL(u)
+		if err != nil {
+			return nil, err
+		}
+		pool.urls = append(pool.urls, &baseURL{url: normalized})
+	}
+	return pool, nil
+}
+
+// parseBaseURL validates u and returns it without trailing slash
+func parseBaseURL(u string) (string, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "", fmt.Errorf("invalid base url %s, %v", u, err)
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("invalid base url %s, scheme and host are required", u)
+	}
+	return strings.TrimSuffix(u, "/"), nil
+}
+
+// candidates returns the healthy base URLs in order of preference, followed by the
+// unhealthy ones in the order they recover
+func (p *endpointPool) candidates() []*baseURL {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.candidatesLocked()
+}
+
+// candidatesLocked is candidates with p.mu held
+func (p *endpointPool) candidatesLocked() []*baseURL {
+	now := p.now()
+	var healthy, unhealthy []*baseURL
+	for _, u := range p.urls {
+		if now.Before(u.downUntil) {
+			unhealthy = append(unhealthy, u)
+		} else {
+			healthy = append(healthy, u)
+		}
+	}
+	sort.SliceStable(unhealthy, func(i, j int) bool { return unhealthy[i].downUntil.Before(unhealthy[j].downUntil) })
+	return append(healthy, unhealthy...)
+}
+
+// stickyURL returns the base URL of signed requests. It stays the same while it is
+// healthy, so nonces of consecutive requests reach the same host.
+func (p *endpointPool) stickyURL() *baseURL {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sticky == nil || p.now().Before(p.sticky.downUntil) {
+		p.sticky = p.candidatesLocked()[0]
+	}
+	return p.sticky
+}
+
+// report marks u healthy or unhealthy after a request
+func (p *endpointPool) report(u *baseURL, healthy bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if healthy {
+		u.downUntil = time.Time{}
+	} else {
+		u.downUntil = p.now().Add(p.cooldown)
+	}
+}
+
+// hostFailed reports whether a request failed because of the host rather than the
+// request or the caller
+func hostFailed(ctx context.Context, resp *response, err error) bool {
+	if err != nil {
+		var urlErr *url.Error
+		return errors.As(err, &urlErr) && ctx.Err() == nil
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// baseURLs returns the base URLs to try for a request in order
+func (c *client) baseURLs(private bool) []*baseURL {
+	if c.endpoints == nil {
+		return []*baseURL{{url: c.url}}
+	}
+	if private {
+		return []*baseURL{c.endpoints.stickyURL()}
+	}
+	return c.endpoints.candidates()
+}
+
+// sendFailover sends a request built by send for each base URL until one does not
+// fail because of its host. Signed requests are only sent to the sticky base URL.
+func (c *client) sendFailover(ctx context.Context, private bool, send func(base string) (*response, error)) (*response, error) {
+	candidates := c.baseURLs(private)
+	for i, base := range candidates {
+		resp, err := send(base.url)
+		if c.endpoints == nil {
+			return resp, err
+		}
+		failed := hostFailed(ctx, resp, err)
+		if err == nil || failed {
+			c.endpoints.report(base, !failed)
+		}
+		if !failed || i == len(candidates)-1 {
+			return resp, err
+		}
+		c.log().Warn("p2pb2b base url failed, failing over",
+			"baseURL", base.url,
+			"next", candidates[i+1].url)
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}
+	return nil, fmt.Errorf("no base url available")
+}
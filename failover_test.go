@@ -0,0 +1,163 @@
+package p2pb2b
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failoverServer struct {
+	*httptest.Server
+	calls  int32
+	status int32
+}
+
+func newFailoverServer() *failoverServer {
+	s := &failoverServer{status: http.StatusOK}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.calls, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&s.status)))
+		if r.Method == "POST" {
+			w.Write([]byte(`{"success":true,"message":"","result":{}}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"message":"","result":[]}`))
+	}))
+	return s
+}
+
+func (s *failoverServer) takeCalls() int32 {
+	return atomic.SwapInt32(&s.calls, 0)
+}
+
+func newFailoverClient(t *testing.T, clock *fakeClock, urls ...string) *client {
+	c, err := newClient(StaticCredentials("key", "secret"), WithFailover(FailoverConfig{BaseURLs: urls, Cooldown: time.Minute}))
+	assert.Nil(t, err)
+	c.endpoints.now = clock.Now
+	return c
+}
+
+func TestFailoverPublic(t *testing.T) {
+	primary, secondary := newFailoverServer(), newFailoverServer()
+	defer primary.Close()
+	defer secondary.Close()
+	clock := &fakeClock{now: time.Unix(1574197772, 0)}
+	c := newFailoverClient(t, clock, primary.URL, secondary.URL+"/")
+
+	atomic.StoreInt32(&primary.status, http.StatusBadGateway)
+	_, err := c.GetMarkets()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), primary.takeCalls())
+	assert.Equal(t, int32(1), secondary.takeCalls())
+
+	// the unhealthy primary is skipped during the cooldown
+	_, err = c.GetMarkets()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(0), primary.takeCalls())
+	assert.Equal(t, int32(1), secondary.takeCalls())
+
+	// and preferred again afterwards
+	atomic.StoreInt32(&primary.status, http.StatusOK)
+	clock.now = clock.now.Add(time.Minute)
+	_, err = c.GetMarkets()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), primary.takeCalls())
+	assert.Equal(t, int32(0), secondary.takeCalls())
+}
+
+func TestFailoverTransportError(t *testing.T) {
+	down, up := newFailoverServer(), newFailoverServer()
+	defer up.Close()
+	down.Close()
+	c := newFailoverClient(t, &fakeClock{now: time.Unix(1574197772, 0)}, down.URL, up.URL)
+
+	_, err := c.GetSymbols()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), up.takeCalls())
+}
+
+func TestFailoverSignedIsSticky(t *testing.T) {
+	primary, secondary := newFailoverServer(), newFailoverServer()
+	defer primary.Close()
+	defer secondary.Close()
+	clock := &fakeClock{now: time.Unix(1574197772, 0)}
+	c := newFailoverClient(t, clock, primary.URL, secondary.URL)
+
+	for i := 0; i < 3; i++ {
+		_, err := c.PostBalances(&AccountBalancesRequest{})
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, int32(3), primary.takeCalls())
+
+	// a failed signed request is not resent to another base URL
+	atomic.StoreInt32(&primary.status, http.StatusInternalServerError)
+	_, err := c.PostBalances(&AccountBalancesRequest{})
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(1), primary.takeCalls())
+	assert.Equal(t, int32(0), secondary.takeCalls())
+
+	// the next one switches, and stays on the new base URL after the primary recovered
+	_, err = c.PostBalances(&AccountBalancesRequest{})
+	assert.Nil(t, err)
+	atomic.StoreInt32(&primary.status, http.StatusOK)
+	clock.now = clock.now.Add(time.Minute)
+	_, err = c.PostBalances(&AccountBalancesRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, int32(0), primary.takeCalls())
+	assert.Equal(t, int32(2), secondary.takeCalls())
+
+	// public requests still prefer the healthy primary
+	_, err = c.GetMarkets()
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), primary.takeCalls())
+}
+
+func TestFailoverStickyURLConcurrent(t *testing.T) {
+	pool, err := newEndpointPool(FailoverConfig{BaseURLs: []string{"https://a.example.com", "https://b.example.com", "https://c.example.com"}})
+	assert.Nil(t, err)
+	pool.report(pool.stickyURL(), false)
+
+	// concurrent signed requests agree on the new sticky base URL
+	urls := make(chan *baseURL, 50)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(urls); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			urls <- pool.stickyURL()
+		}()
+	}
+	wg.Wait()
+	close(urls)
+	for u := range urls {
+		assert.Equal(t, "https://b.example.com", u.url)
+	}
+}
+
+func TestFailoverMetricsEndpointName(t *testing.T) {
+	server := newFailoverServer()
+	defer server.Close()
+	metrics := NewMetrics()
+	c, err := NewPublicClient(WithFailover(FailoverConfig{BaseURLs: []string{server.URL + "/api/v1"}}), WithMetrics(metrics))
+	assert.Nil(t, err)
+	_, err = c.GetMarkets()
+	assert.Nil(t, err)
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, recorder.Body.String(), `p2pb2b_requests_total{endpoint="/public/markets",status="200"} 1`)
+}
+
+func TestFailoverInvalidConfig(t *testing.T) {
+	_, err := NewClient("key", "secret", WithFailover(FailoverConfig{}))
+	assert.NotNil(t, err)
+	_, err = NewClient("key", "secret", WithFailover(FailoverConfig{BaseURLs: []string{"api.p2pb2b.io"}}))
+	assert.NotNil(t, err)
+	_, err = NewClient("key", "secret", WithFailover(FailoverConfig{BaseURLs: []string{baseAPI}, Cooldown: -time.Second}))
+	assert.NotNil(t, err)
+}
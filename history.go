@@ -1,10 +1,9 @@
 package p2pb2b
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"net/url"
 )
 
 type HistoryResp struct {
@@ -18,11 +17,15 @@ type HistoryEntry struct {
 	ID     int     `json:"id"`
 	Type   string  `json:"type"`
 	Time   float64 `json:"time"`
-	Amount float64 `json:"amount,string"`
-	Price  float64 `json:"price,string"`
+	Amount Decimal `json:"amount"`
+	Price  Decimal `json:"price"`
 }
 
 func (c *client) GetHistory(market string, lastID int64, limit int64) (*HistoryResp, error) {
+	return c.GetHistoryCtx(context.Background(), market, lastID, limit)
+}
+
+func (c *client) GetHistoryCtx(ctx context.Context, market string, lastID int64, limit int64) (*HistoryResp, error) {
 	if market == "" {
 		return nil, fmt.Errorf("parameter market must not be empty")
 	}
@@ -33,25 +36,10 @@ func (c *client) GetHistory(market string, lastID int64, limit int64) (*HistoryR
 		return nil, fmt.Errorf("parameter limit must not be <= 0")
 	}
 
-	url := fmt.Sprintf("%s/public/history?market=%s&lastId=%d&limit=%d", c.url, market, lastID, limit)
-
-	resp, err := c.sendGet(url, nil)
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	query := fmt.Sprintf("market=%s&lastId=%d&limit=%d", url.QueryEscape(market), lastID, limit)
 
 	var result HistoryResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, historyEndpoint, query, nil, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -70,13 +70,13 @@ func TestGetHistory(t *testing.T) {
 	assert.Equal(t, 160354369, resp.Result[0].ID)
 	assert.Equal(t, "sell", resp.Result[0].Type)
 	assert.Equal(t, 1574195085.511277, resp.Result[0].Time)
-	assert.Equal(t, 0.174, resp.Result[0].Amount)
-	assert.Equal(t, 0.021427, resp.Result[0].Price)
+	assert.Equal(t, "0.174", resp.Result[0].Amount.String())
+	assert.Equal(t, "0.021427", resp.Result[0].Price.String())
 	assert.Equal(t, 160354368, resp.Result[1].ID)
 	assert.Equal(t, "sell", resp.Result[1].Type)
 	assert.Equal(t, 1574195085.511159, resp.Result[1].Time)
-	assert.Equal(t, 0.501, resp.Result[1].Amount)
-	assert.Equal(t, 0.021427, resp.Result[1].Price)
+	assert.Equal(t, "0.501", resp.Result[1].Amount.String())
+	assert.Equal(t, "0.021427", resp.Result[1].Price.String())
 }
 
 func TestGetHistoryNegative(t *testing.T) {
@@ -2,14 +2,15 @@ package p2pb2b
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
+	"context"
 	"encoding/base64"
-	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 )
 
 const (
@@ -17,19 +18,39 @@ const (
 	HeaderXTxcAPIKey = "X-TXC-APIKEY"
 	// HeaderXTxcPayloard is the HTTP Header for body json encoded in base64
 	HeaderXTxcPayloard = "X-TXC-PAYLOAD"
-	// HeaderXTxcSignature is the HTTP Header for encrypted in HmacSHA512 payload
+	// HeaderXTxcSignature is the HTTP Header for the hex encoded HMAC signature of the payload
 	HeaderXTxcSignature = "X-TXC-SIGNATURE"
 )
 
-type auth struct {
-	APIKey    string
-	APISecret string
-}
-
 type client struct {
-	http *http.Client
-	auth *auth
-	url  string
+	http        *http.Client
+	credentials CredentialsProvider
+	url         string
+	userAgent   string
+	nonces      NonceSource
+	retry       RetryPolicy
+	endpoints   *endpointPool
+	limiter     *rateLimiter
+	breakers    *circuitBreakers
+	logger      Logger
+	metrics     MetricsRecorder
+	cache       *ResponseCache
+	coalescer   *RequestCoalescer
+	signer      Signer
+	clock       *ServerClock
+
+	signatureAlgorithm SignatureAlgorithm
+	maxResponseSize    int64
+
+	middlewares []Middleware
+	doer        Doer
+	dryRun      *dryRun
+
+	orderReconciler OrderReconciler
+
+	// only used while applying options
+	timeout   *time.Duration
+	transport http.RoundTripper
 }
 
 type response struct {
@@ -37,15 +58,87 @@ type response struct {
 	Body       io.ReadCloser
 	StatusCode int
 	Status     string
+	Endpoint   string
+
+	endpointName string
+	metrics      MetricsRecorder
+	// sent and received are the local times of the HTTP exchange, zero for cached responses
+	sent      time.Time
+	received  time.Time
+	cached    bool
+	coalesced bool
 }
 
+// checkHTTPStatus returns an APIError containing the response body if the status is not expected
 func checkHTTPStatus(resp response, expected ...int) error {
 	for _, e := range expected {
 		if resp.StatusCode == e {
 			return nil
 		}
 	}
-	return fmt.Errorf("http response status != %+v, got %d", expected, resp.StatusCode)
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil {
+		return fmt.Errorf("http response status != %+v, got %d", expected, resp.StatusCode)
+	}
+	return newAPIError(resp, body)
+}
+
+// fillRequest sets the request path and nonce of a signed request if they are not set yet
+func (c *client) fillRequest(request *Request, endpointURL string) error {
+	if request.Request == "" {
+		u, err := url.Parse(endpointURL)
+		if err != nil {
+			return fmt.Errorf("error parsing endpoint url, %v", err)
+		}
+		request.Request = u.Path
+	}
+	if request.Nonce == "" {
+		nonce, err := c.nonces.NextNonce()
+		if err != nil {
+			return fmt.Errorf("error generating nonce, %v", err)
+		}
+		request.Nonce = strconv.FormatInt(nonce, 10)
+	}
+	return nil
+}
+
+// privateCredentials returns the credentials for a private request to path, or an
+// error wrapping ErrNoCredentials if the client has no API key or secret
+func (c *client) privateCredentials(path string) (Credentials, error) {
+	if c.credentials == nil {
+		return Credentials{}, fmt.Errorf("private endpoint %s requires credentials, %w", path, ErrNoCredentials)
+	}
+	creds, err := c.credentials.Credentials()
+	if err != nil {
+		return Credentials{}, fmt.Errorf("error reading credentials, %w", err)
+	}
+	if creds.APIKey == "" || (creds.APISecret == "" && c.signer == nil) {
+		return Credentials{}, fmt.Errorf("private endpoint %s requires an API key and secret, %w", path, ErrNoCredentials)
+	}
+	return creds, nil
+}
+
+// requestSigner returns the signer of private requests using creds
+func (c *client) requestSigner(creds Credentials) (Signer, error) {
+	if c.signer != nil {
+		return c.signer, nil
+	}
+	return NewHMACSigner(c.signatureAlgorithm, creds.APISecret)
+}
+
+func (c *client) log() Logger {
+	if c.logger == nil {
+		return noopLogger{}
+	}
+	return c.logger
+}
+
+// doRequest sends request through the middleware chain
+func (c *client) doRequest(request *http.Request) (*http.Response, error) {
+	if c.doer == nil {
+		return c.http.Do(request)
+	}
+	return c.doer.Do(request)
 }
 
 func mergeHeaders(firstHeaders map[string]string, secondHeaders map[string]string) map[string]string {
@@ -63,12 +156,12 @@ func mergeHeaders(firstHeaders map[string]string, secondHeaders map[string]strin
 	return firstHeaders
 }
 
-func (c *client) sendPost(url string, additionalHeaders map[string]string, body io.Reader) (*response, error) {
+func (c *client) sendPost(ctx context.Context, url string, additionalHeaders map[string]string, body io.Reader) (*response, error) {
 	bodyBytes, err := ioutil.ReadAll(body)
 	if err != nil {
 		return nil, err
 	}
-	req, err := http.NewRequest("POST", url, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return &response{}, fmt.Errorf("error creating POST request, %v", err)
 	}
@@ -78,50 +171,143 @@ func (c *client) sendPost(url string, additionalHeaders map[string]string, body
 	}
 	additionalHeaders[HeaderXTxcPayloard] = base64.StdEncoding.EncodeToString(bodyBytes)
 
-	if c.auth != nil {
-		h := hmac.New(sha256.New, []byte(c.auth.APISecret))
-		h.Write(bodyBytes)
-		signature := hex.EncodeToString(h.Sum(nil))
-		additionalHeaders[HeaderXTxcSignature] = signature
+	// read the credentials once, so key and signature match even while they are rotated
+	creds, err := c.privateCredentials(req.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+	additionalHeaders[HeaderXTxcAPIKey] = creds.APIKey
+
+	signer, err := c.requestSigner(creds)
+	if err != nil {
+		return nil, err
+	}
+	signatureHeaders, err := signer.Sign(bodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error signing request, %v", err)
+	}
+	for k, v := range signatureHeaders {
+		additionalHeaders[k] = v
 	}
 
-	return c.sendRequest(req, additionalHeaders)
+	return c.sendRequest(classPrivate, req, additionalHeaders)
 }
 
-func (c *client) sendGet(url string, additionalHeaders map[string]string) (*response, error) {
-	req, err := http.NewRequest("GET", url, nil)
+func (c *client) sendGet(ctx context.Context, url string, additionalHeaders map[string]string) (*response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 
 	if err != nil {
 		return &response{}, fmt.Errorf("error creating GET request, %v", err)
 	}
 
-	return c.sendRequest(req, additionalHeaders)
-}
-
-func (c *client) sendRequest(request *http.Request, additionalHeaders map[string]string) (*response, error) {
+	if c.cache == nil {
+		return c.sendRequest(classPublic, req, additionalHeaders)
+	}
 
-	for k, v := range additionalHeaders {
-		request.Header.Add(k, v)
+	req = withEndpointName(req, c.url)
+	name := EndpointName(req)
+	if cached, ok := c.cache.get(url, name); ok {
+		cached.Endpoint = req.URL.Path
+		cached.endpointName = name
+		cached.metrics = c.metrics
+		return cached, nil
+	}
+	resp, err := c.sendRequest(classPublic, req, additionalHeaders)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
 	}
+	body, err := readLimited(resp.Body, c.maxResponseSize)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading response of %s, %w", req.URL.Path, err)
+	}
+	c.cache.put(url, name, resp.Header, body)
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func (c *client) sendRequest(class endpointClass, request *http.Request, additionalHeaders map[string]string) (*response, error) {
+	request = withEndpointName(request, c.url)
 
 	thisHeaders := map[string]string{}
 	thisHeaders["Content-type"] = "application/json"
-	if c.auth != nil {
-		thisHeaders[HeaderXTxcAPIKey] = c.auth.APIKey
+	if _, ok := additionalHeaders[HeaderXTxcAPIKey]; !ok && c.credentials != nil {
+		// public requests do not need credentials, so they are only sent if available
+		if creds, err := c.credentials.Credentials(); err == nil && creds.APIKey != "" {
+			thisHeaders[HeaderXTxcAPIKey] = creds.APIKey
+		}
+	}
+	if c.userAgent != "" {
+		thisHeaders["User-Agent"] = c.userAgent
 	}
 	headers := mergeHeaders(additionalHeaders, thisHeaders)
 	for k, v := range headers {
-		request.Header.Add(k, v)
+		request.Header.Set(k, v)
+	}
+	if c.dryRun.intercepts(request) {
+		// dry-run trading requests pass the middlewares but are never sent, so they use no
+		// tokens of the rate limiter and are not counted by the breakers and metrics
+		resp, err := c.doRequest(request)
+		if err != nil {
+			return nil, err
+		}
+		return &response{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Header:     resp.Header,
+			Body:       resp.Body,
+			Endpoint:   request.URL.Path,
+
+			endpointName: EndpointName(request),
+		}, nil
 	}
-	resp, err := c.http.Do(request)
+	generation, err := c.breakers.allow(class)
 	if err != nil {
-		fmt.Println(fmt.Sprintf("erro: %v", err))
 		return nil, err
 	}
+	if err := c.limiter.wait(request.Context(), class); err != nil {
+		c.breakers.release(class, generation)
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := c.doRequest(request)
+	latency := time.Since(start)
+	c.breakers.done(class, generation, request, resp, err)
+	if err != nil {
+		c.log().Warn("p2pb2b request failed",
+			"method", request.Method,
+			"endpoint", request.URL.Path,
+			"latency", latency,
+			"error", err)
+		if c.metrics != nil {
+			c.metrics.ObserveRequest(EndpointName(request), 0, latency)
+		}
+		return nil, err
+	}
+	if c.metrics != nil {
+		c.metrics.ObserveRequest(EndpointName(request), resp.StatusCode, latency)
+	}
+	c.log().Debug("p2pb2b request",
+		"method", request.Method,
+		"endpoint", request.URL.Path,
+		"latency", latency,
+		"status", resp.StatusCode,
+		"requestHeaders", redactHeaders(request.Header),
+		"responseHeaders", redactHeaders(resp.Header))
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter, _ := retryAfter(resp.Header)
+		c.limiter.throttled(class, retryAfter)
+	}
 	return &response{
 		StatusCode: resp.StatusCode,
 		Status:     resp.Status,
 		Header:     resp.Header,
 		Body:       resp.Body,
+		Endpoint:   request.URL.Path,
+
+		endpointName: EndpointName(request),
+		metrics:      c.metrics,
+		sent:         start,
+		received:     start.Add(latency),
 	}, nil
 }
@@ -1,6 +1,7 @@
 package p2pb2b
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -44,15 +45,12 @@ func TestSendGet(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	auth := &auth{
-		APIKey: "Ireallydontcare",
-	}
 	client := &client{
-		http: &http.Client{},
-		auth: auth,
+		http:        &http.Client{},
+		credentials: StaticCredentials("Ireallydontcare", ""),
 	}
 	headers := map[string]string{}
-	_, err := client.sendGet(fmt.Sprintf("%s/%s", ts.URL, "somePath"), headers)
+	_, err := client.sendGet(context.Background(), fmt.Sprintf("%s/%s", ts.URL, "somePath"), headers)
 	if err != nil {
 		t.Errorf("error in SendGet, %v", err)
 	}
@@ -66,14 +64,11 @@ func TestSendGetWithAdditionalHeaders(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	auth := &auth{
-		APIKey: "Ireallydontcare",
-	}
 	client := &client{
-		http: &http.Client{},
-		auth: auth,
+		http:        &http.Client{},
+		credentials: StaticCredentials("Ireallydontcare", ""),
 	}
-	_, err := client.sendGet(fmt.Sprintf("%s/%s", ts.URL, "somePath"), nil)
+	_, err := client.sendGet(context.Background(), fmt.Sprintf("%s/%s", ts.URL, "somePath"), nil)
 	if err != nil {
 		t.Errorf("error in SendGet, %v", err)
 	}
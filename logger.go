@@ -0,0 +1,37 @@
+package p2pb2b
+
+import (
+	"net/http"
+)
+
+// Logger is a leveled structured logger. keysAndValues are alternating keys and
+// values, e.g. "endpoint", "/api/v1/order/new", "status", 200.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(msg string, keysAndValues ...interface{}) {}
+func (noopLogger) Info(msg string, keysAndValues ...interface{})  {}
+func (noopLogger) Warn(msg string, keysAndValues ...interface{})  {}
+func (noopLogger) Error(msg string, keysAndValues ...interface{}) {}
+
+const redacted = "[REDACTED]"
+
+// redactedHeaders are never logged in clear text
+var redactedHeaders = []string{HeaderXTxcAPIKey, HeaderXTxcSignature, "Authorization"}
+
+// redactHeaders returns a copy of header with credentials and signatures replaced
+func redactHeaders(header http.Header) http.Header {
+	result := header.Clone()
+	for _, h := range redactedHeaders {
+		if result.Get(h) != "" {
+			result.Set(h, redacted)
+		}
+	}
+	return result
+}
@@ -0,0 +1,95 @@
+package p2pb2b
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type logEntry struct {
+	level  string
+	msg    string
+	fields map[string]interface{}
+}
+
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) log(level string, msg string, keysAndValues ...interface{}) {
+	fields := map[string]interface{}{}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[fmt.Sprint(keysAndValues[i])] = keysAndValues[i+1]
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, fields: fields})
+}
+
+func (l *recordingLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.log("debug", msg, keysAndValues...)
+}
+
+func (l *recordingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.log("info", msg, keysAndValues...)
+}
+
+func (l *recordingLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.log("warn", msg, keysAndValues...)
+}
+
+func (l *recordingLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.log("error", msg, keysAndValues...)
+}
+
+func TestLoggerRedactsCredentials(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":{}}`))
+	}))
+	defer ts.Close()
+
+	logger := &recordingLogger{}
+	client, err := newClientWithURL(ts.URL, "mySecretKey", "mySecretSecret", WithLogger(logger))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	assert.Nil(t, err)
+
+	assert.Equal(t, 1, len(logger.entries))
+	entry := logger.entries[0]
+	assert.Equal(t, "debug", entry.level)
+	assert.Equal(t, "POST", entry.fields["method"])
+	assert.Equal(t, "/account/balances", entry.fields["endpoint"])
+	assert.Equal(t, http.StatusOK, entry.fields["status"])
+	assert.NotNil(t, entry.fields["latency"])
+
+	headers := entry.fields["requestHeaders"].(http.Header)
+	assert.Equal(t, redacted, headers.Get(HeaderXTxcAPIKey))
+	assert.Equal(t, redacted, headers.Get(HeaderXTxcSignature))
+	assert.NotEmpty(t, headers.Get(HeaderXTxcPayloard))
+	for _, e := range logger.entries {
+		assert.False(t, strings.Contains(fmt.Sprint(e.fields), "mySecret"))
+	}
+}
+
+func TestLoggerTransportError(t *testing.T) {
+	logger := &recordingLogger{}
+	client, err := newClientWithURL("http://127.0.0.1:1", "key", "secret", WithLogger(logger))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	_, err = client.GetMarkets()
+	assert.NotNil(t, err)
+
+	assert.Equal(t, 1, len(logger.entries))
+	assert.Equal(t, "warn", logger.entries[0].level)
+	assert.Equal(t, err, logger.entries[0].fields["error"])
+}
@@ -1,10 +1,7 @@
 package p2pb2b
 
 import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
+	"context"
 )
 
 type MarketsResp struct {
@@ -21,28 +18,16 @@ type Market struct {
 	Money     string  `json:"money"`
 	StockPrec int     `json:"stockPrec,string"`
 	FeePrec   int     `json:"feePrec,string"`
-	MinAmount float64 `json:"minAmount,string"`
+	MinAmount Decimal `json:"minAmount"`
 }
 
 func (c *client) GetMarkets() (*MarketsResp, error) {
-	url := fmt.Sprintf("%s/public/markets", c.url)
-	resp, err := c.sendGet(url, nil)
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return c.GetMarketsCtx(context.Background())
+}
 
+func (c *client) GetMarketsCtx(ctx context.Context) (*MarketsResp, error) {
 	var result MarketsResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, marketsEndpoint, "", nil, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
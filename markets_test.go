@@ -77,7 +77,7 @@ func TestGetMarkets(t *testing.T) {
 	assert.Equal(t, 6, resp.Result[0].MoneyPrec)
 	assert.Equal(t, 3, resp.Result[0].StockPrec)
 	assert.Equal(t, 4, resp.Result[0].FeePrec)
-	assert.Equal(t, 0.001, resp.Result[0].MinAmount)
+	assert.Equal(t, "0.001", resp.Result[0].MinAmount.String())
 
 	assert.Equal(t, "BTC_USD", resp.Result[1].Name)
 	assert.Equal(t, "BTC", resp.Result[1].Stock)
@@ -85,5 +85,5 @@ func TestGetMarkets(t *testing.T) {
 	assert.Equal(t, 2, resp.Result[1].MoneyPrec)
 	assert.Equal(t, 6, resp.Result[1].StockPrec)
 	assert.Equal(t, 4, resp.Result[1].FeePrec)
-	assert.Equal(t, 0.001, resp.Result[1].MinAmount)
+	assert.Equal(t, "0.001", resp.Result[1].MinAmount.String())
 }
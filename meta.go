@@ -0,0 +1,86 @@
+package p2pb2b
+
+import (
+	"net/http"
+	"time"
+)
+
+// ResponseMeta is the raw HTTP metadata of a response, e.g. to read rate limit headers
+// or to log the exact payload when the schema of the exchange changes
+type ResponseMeta struct {
+	// Endpoint is the path of the called endpoint, e.g. /api/v1/public/ticker
+	Endpoint string
+	// StatusCode and Status are the HTTP status of the response
+	StatusCode int
+	Status     string
+	// Header are the HTTP headers of the response
+	Header http.Header
+	// Latency is the duration of the HTTP exchange, 0 for cached responses
+	Latency time.Duration
+	// Body is the raw JSON body, truncated to 64 KiB
+	Body []byte
+	// BodyTruncated reports whether Body is only the start of the body
+	BodyTruncated bool
+	// Cached reports whether the response was served from the ResponseCache
+	Cached bool
+	// Coalesced reports whether the response was shared with a concurrent identical call
+	Coalesced bool
+}
+
+// Meta returns the raw metadata of the response, nil if it was not received by a client
+func (r *Response) Meta() *ResponseMeta {
+	return r.meta
+}
+
+func (r *Response) setMeta(meta *ResponseMeta) {
+	r.meta = meta
+}
+
+// Meta returns the raw metadata of the response, nil if it was not received by a client
+func (r *CreateOrderResp) Meta() *ResponseMeta {
+	return r.meta
+}
+
+func (r *CreateOrderResp) setMeta(meta *ResponseMeta) {
+	r.meta = meta
+}
+
+// Meta returns the raw metadata of the response, nil if it was not received by a client
+func (r *CancelOrderResp) Meta() *ResponseMeta {
+	return r.meta
+}
+
+func (r *CancelOrderResp) setMeta(meta *ResponseMeta) {
+	r.meta = meta
+}
+
+// Meta returns the raw metadata of the response, nil if it was not received by a client
+func (r *QueryUnexecutedResp) Meta() *ResponseMeta {
+	return r.meta
+}
+
+func (r *QueryUnexecutedResp) setMeta(meta *ResponseMeta) {
+	r.meta = meta
+}
+
+// metaReceiver is implemented by all response structs
+type metaReceiver interface {
+	setMeta(meta *ResponseMeta)
+}
+
+func newResponseMeta(resp response, body *captureBuffer) *ResponseMeta {
+	meta := &ResponseMeta{
+		Endpoint:      resp.Endpoint,
+		StatusCode:    resp.StatusCode,
+		Status:        resp.Status,
+		Header:        resp.Header,
+		Body:          body.buf,
+		BodyTruncated: body.truncated,
+		Cached:        resp.cached,
+		Coalesced:     resp.coalesced,
+	}
+	if !resp.cached {
+		meta.Latency = resp.received.Sub(resp.sent)
+	}
+	return meta
+}
@@ -0,0 +1,77 @@
+package p2pb2b
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseMeta(t *testing.T) {
+	body := `{"success":true,"message":"","result":["ETH_BTC"],"cache_time":1574197248.425589,"current_time":1574197248.425589}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "9")
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL, "key", "secret", WithResponseCache(NewResponseCache(CacheConfig{})))
+	assert.Nil(t, err)
+
+	resp, err := client.GetSymbols()
+	assert.Nil(t, err)
+	meta := resp.Meta()
+	if assert.NotNil(t, meta) {
+		assert.Equal(t, "/public/symbols", meta.Endpoint)
+		assert.Equal(t, http.StatusOK, meta.StatusCode)
+		assert.Equal(t, "9", meta.Header.Get("X-RateLimit-Remaining"))
+		assert.Equal(t, body, string(meta.Body))
+		assert.False(t, meta.BodyTruncated)
+		assert.False(t, meta.Cached)
+		assert.True(t, meta.Latency > 0)
+	}
+
+	resp, err = client.GetSymbols()
+	assert.Nil(t, err)
+	if assert.NotNil(t, resp.Meta()) {
+		assert.True(t, resp.Meta().Cached)
+		assert.Equal(t, body, string(resp.Meta().Body))
+	}
+
+	assert.Nil(t, (&SymbolsResp{}).Meta())
+}
+
+func TestResponseMetaTruncated(t *testing.T) {
+	symbols := `"` + strings.Repeat("A", maxErrorBodySize) + `"`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"message":"","result":[` + symbols + `]}`))
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL, "key", "secret")
+	assert.Nil(t, err)
+	resp, err := client.GetSymbols()
+	assert.Nil(t, err)
+	assert.True(t, resp.Meta().BodyTruncated)
+	assert.Len(t, resp.Meta().Body, maxErrorBodySize)
+}
+
+func TestResponseMetaOfOrders(t *testing.T) {
+	body := `{"success":true,"message":"","result":{"orderId":25749,"market":"ETH_BTC","price":"0.1","side":"buy","type":"limit","amount":"1","left":"1"}}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL, "key", "secret")
+	assert.Nil(t, err)
+	resp, err := client.CreateOrder(&CreateOrderRequest{Market: "ETH_BTC", Side: "buy", Amount: NewDecimalFromInt(1), Price: MustParseDecimal("0.1")})
+	assert.Nil(t, err)
+	assert.True(t, resp.Success)
+	if assert.NotNil(t, resp.Meta()) {
+		assert.Equal(t, "/order/new", resp.Meta().Endpoint)
+		assert.Equal(t, body, string(resp.Meta().Body))
+	}
+}
@@ -0,0 +1,164 @@
+package p2pb2b
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsRecorder receives measurements of every request sent by the client.
+// endpoint is the name returned by EndpointName, e.g. /order/new.
+type MetricsRecorder interface {
+	// ObserveRequest is called for every HTTP request, statusCode is 0 if the request failed without response
+	ObserveRequest(endpoint string, statusCode int, latency time.Duration)
+	// ObserveAPIError is called for every APIError with the class returned by ErrorClass
+	ObserveAPIError(endpoint string, class string)
+}
+
+// DefaultLatencyBuckets are the upper bounds in seconds of the latency histogram used by NewMetrics
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics is a MetricsRecorder collecting request counts, latency histograms and API
+// error counts per endpoint. It is an http.Handler exposing them in the Prometheus
+// text exposition format.
+type Metrics struct {
+	mu        sync.Mutex
+	buckets   []float64
+	endpoints map[string]*endpointMetrics
+}
+
+type endpointMetrics struct {
+	requests     map[string]uint64
+	bucketCounts []uint64
+	latencySum   float64
+	latencyCount uint64
+	apiErrors    map[string]uint64
+}
+
+// NewMetrics creates Metrics with the given latency buckets in seconds, DefaultLatencyBuckets if none are given
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &Metrics{
+		buckets:   sorted,
+		endpoints: map[string]*endpointMetrics{},
+	}
+}
+
+func (m *Metrics) endpoint(name string) *endpointMetrics {
+	e, ok := m.endpoints[name]
+	if !ok {
+		e = &endpointMetrics{
+			requests:     map[string]uint64{},
+			bucketCounts: make([]uint64, len(m.buckets)),
+			apiErrors:    map[string]uint64{},
+		}
+		m.endpoints[name] = e
+	}
+	return e
+}
+
+// ObserveRequest implements MetricsRecorder
+func (m *Metrics) ObserveRequest(endpoint string, statusCode int, latency time.Duration) {
+	status := "error"
+	if statusCode != 0 {
+		status = strconv.Itoa(statusCode)
+	}
+	seconds := latency.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.endpoint(endpoint)
+	e.requests[status]++
+	for i, upper := range m.buckets {
+		if seconds <= upper {
+			e.bucketCounts[i]++
+		}
+	}
+	e.latencySum += seconds
+	e.latencyCount++
+}
+
+// ObserveAPIError implements MetricsRecorder
+func (m *Metrics) ObserveAPIError(endpoint string, class string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.endpoint(endpoint).apiErrors[class]++
+}
+
+// ServeHTTP writes all metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	buf := bufio.NewWriter(w)
+	m.write(buf)
+	buf.Flush()
+}
+
+func (m *Metrics) write(w *bufio.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.endpoints))
+	for name := range m.endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "# HELP p2pb2b_requests_total Number of HTTP requests sent to the exchange.")
+	fmt.Fprintln(w, "# TYPE p2pb2b_requests_total counter")
+	for _, name := range names {
+		requests := m.endpoints[name].requests
+		for _, status := range sortedKeys(requests) {
+			fmt.Fprintf(w, "p2pb2b_requests_total{endpoint=\"%s\",status=\"%s\"} %d\n",
+				escapeLabel(name), status, requests[status])
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP p2pb2b_request_duration_seconds Latency of HTTP requests sent to the exchange.")
+	fmt.Fprintln(w, "# TYPE p2pb2b_request_duration_seconds histogram")
+	for _, name := range names {
+		e := m.endpoints[name]
+		label := escapeLabel(name)
+		for i, upper := range m.buckets {
+			fmt.Fprintf(w, "p2pb2b_request_duration_seconds_bucket{endpoint=\"%s\",le=\"%s\"} %d\n",
+				label, strconv.FormatFloat(upper, 'g', -1, 64), e.bucketCounts[i])
+		}
+		fmt.Fprintf(w, "p2pb2b_request_duration_seconds_bucket{endpoint=\"%s\",le=\"+Inf\"} %d\n", label, e.latencyCount)
+		fmt.Fprintf(w, "p2pb2b_request_duration_seconds_sum{endpoint=\"%s\"} %s\n",
+			label, strconv.FormatFloat(e.latencySum, 'g', -1, 64))
+		fmt.Fprintf(w, "p2pb2b_request_duration_seconds_count{endpoint=\"%s\"} %d\n", label, e.latencyCount)
+	}
+
+	fmt.Fprintln(w, "# HELP p2pb2b_api_errors_total Number of API errors returned by the exchange.")
+	fmt.Fprintln(w, "# TYPE p2pb2b_api_errors_total counter")
+	for _, name := range names {
+		apiErrors := m.endpoints[name].apiErrors
+		for _, class := range sortedKeys(apiErrors) {
+			fmt.Fprintf(w, "p2pb2b_api_errors_total{endpoint=\"%s\",class=\"%s\"} %d\n",
+				escapeLabel(name), escapeLabel(class), apiErrors[class])
+		}
+	}
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
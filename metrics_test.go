@@ -0,0 +1,97 @@
+package p2pb2b
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsFromClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/public/markets":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"success":true,"message":"","result":[]}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"success":false,"message":"Balance not enough","result":[]}`))
+		}
+	}))
+	defer ts.Close()
+
+	metrics := NewMetrics(0.5, 1)
+	client, err := newClientWithURL(ts.URL+"/api/v1", uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithMetrics(metrics))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	_, err = client.GetMarkets()
+	assert.Nil(t, err)
+	_, err = client.GetMarkets()
+	assert.Nil(t, err)
+	_, err = client.CreateOrder(&CreateOrderRequest{Market: "ETH_BTC", Side: "buy", Amount: NewDecimalFromInt(1), Price: NewDecimalFromInt(1)})
+	assert.True(t, IsInsufficientFunds(err))
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+	text := string(body)
+
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, text, `p2pb2b_requests_total{endpoint="/public/markets",status="200"} 2`)
+	assert.Contains(t, text, `p2pb2b_requests_total{endpoint="/order/new",status="200"} 1`)
+	assert.Contains(t, text, `p2pb2b_request_duration_seconds_bucket{endpoint="/public/markets",le="+Inf"} 2`)
+	assert.Contains(t, text, `p2pb2b_request_duration_seconds_count{endpoint="/order/new"} 1`)
+	assert.Contains(t, text, `p2pb2b_api_errors_total{endpoint="/order/new",class="insufficient_funds"} 1`)
+	assert.NotContains(t, text, `p2pb2b_api_errors_total{endpoint="/public/markets"`)
+}
+
+func TestMetricsExposition(t *testing.T) {
+	metrics := NewMetrics(0.1, 1)
+	metrics.ObserveRequest("/public/book", 200, 50*time.Millisecond)
+	metrics.ObserveRequest("/public/book", 200, 500*time.Millisecond)
+	metrics.ObserveRequest("/public/book", 0, 2*time.Second)
+	metrics.ObserveAPIError("/public/\"book\"", "api")
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+
+	assert.Equal(t, []string{
+		"# HELP p2pb2b_requests_total Number of HTTP requests sent to the exchange.",
+		"# TYPE p2pb2b_requests_total counter",
+		`p2pb2b_requests_total{endpoint="/public/book",status="200"} 2`,
+		`p2pb2b_requests_total{endpoint="/public/book",status="error"} 1`,
+		"# HELP p2pb2b_request_duration_seconds Latency of HTTP requests sent to the exchange.",
+		"# TYPE p2pb2b_request_duration_seconds histogram",
+		`p2pb2b_request_duration_seconds_bucket{endpoint="/public/\"book\"",le="0.1"} 0`,
+		`p2pb2b_request_duration_seconds_bucket{endpoint="/public/\"book\"",le="1"} 0`,
+		`p2pb2b_request_duration_seconds_bucket{endpoint="/public/\"book\"",le="+Inf"} 0`,
+		`p2pb2b_request_duration_seconds_sum{endpoint="/public/\"book\""} 0`,
+		`p2pb2b_request_duration_seconds_count{endpoint="/public/\"book\""} 0`,
+		`p2pb2b_request_duration_seconds_bucket{endpoint="/public/book",le="0.1"} 1`,
+		`p2pb2b_request_duration_seconds_bucket{endpoint="/public/book",le="1"} 2`,
+		`p2pb2b_request_duration_seconds_bucket{endpoint="/public/book",le="+Inf"} 3`,
+		`p2pb2b_request_duration_seconds_sum{endpoint="/public/book"} 2.55`,
+		`p2pb2b_request_duration_seconds_count{endpoint="/public/book"} 3`,
+		"# HELP p2pb2b_api_errors_total Number of API errors returned by the exchange.",
+		"# TYPE p2pb2b_api_errors_total counter",
+		`p2pb2b_api_errors_total{endpoint="/public/\"book\"",class="api"} 1`,
+	}, lines)
+}
+
+func TestErrorClass(t *testing.T) {
+	assert.Equal(t, "", ErrorClass(nil))
+	assert.Equal(t, "", ErrorClass(ErrRateLimitExceeded))
+	assert.Equal(t, "auth", ErrorClass(&APIError{StatusCode: http.StatusUnauthorized}))
+	assert.Equal(t, "rate_limited", ErrorClass(&APIError{StatusCode: http.StatusTooManyRequests}))
+	assert.Equal(t, "order_not_found", ErrorClass(&APIError{Message: "Order not found"}))
+	assert.Equal(t, "api", ErrorClass(&APIError{StatusCode: http.StatusBadGateway}))
+}
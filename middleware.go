@@ -0,0 +1,66 @@
+package p2pb2b
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Doer sends a single HTTP request. *http.Client implements Doer.
+type Doer interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to use ordinary functions as Doer
+type DoerFunc func(request *http.Request) (*http.Response, error)
+
+// Do calls f(request)
+func (f DoerFunc) Do(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// Middleware wraps the Doer sending the fully signed requests of the client. It can
+// inspect or modify requests and responses, or short-circuit by not calling next.
+type Middleware func(next Doer) Doer
+
+type endpointKey struct{}
+
+// EndpointName returns the name of the API endpoint a request of the client is sent
+// to, i.e. its path relative to the base URL such as /order/new or /public/book.
+// It returns an empty string for requests not sent by the client.
+func EndpointName(request *http.Request) string {
+	name, _ := request.Context().Value(endpointKey{}).(string)
+	return name
+}
+
+// withEndpointName attaches the endpoint name relative to baseURL to the request,
+// unless its context already names the endpoint
+func withEndpointName(request *http.Request, baseURL string) *http.Request {
+	if EndpointName(request) != "" {
+		return request
+	}
+	name := endpointName(request.URL.Path, baseURL)
+	return request.WithContext(contextWithEndpointName(request.Context(), name))
+}
+
+// contextWithEndpointName names the endpoint of the requests sent with ctx
+func contextWithEndpointName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, endpointKey{}, name)
+}
+
+// endpointName returns path relative to the path of baseURL
+func endpointName(path string, baseURL string) string {
+	if base, err := url.Parse(baseURL); err == nil {
+		return strings.TrimPrefix(path, strings.TrimSuffix(base.Path, "/"))
+	}
+	return path
+}
+
+// chainMiddlewares wraps doer with middlewares, the first middleware being the outermost
+func chainMiddlewares(doer Doer, middlewares []Middleware) Doer {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		doer = middlewares[i](doer)
+	}
+	return doer
+}
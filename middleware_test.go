@@ -0,0 +1,82 @@
+package p2pb2b
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareChain(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "outer,inner", r.Header.Get("X-Audit"))
+		assert.Equal(t, 1, len(r.Header[http.CanonicalHeaderKey(HeaderXTxcPayloard)]))
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":{}}`))
+	}))
+	defer ts.Close()
+
+	var order []string
+	var endpoints []string
+	audit := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(r *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				endpoints = append(endpoints, EndpointName(r))
+				assert.NotEmpty(t, r.Header.Get(HeaderXTxcSignature))
+				if existing := r.Header.Get("X-Audit"); existing != "" {
+					name = existing + "," + name
+				}
+				r.Header.Set("X-Audit", name)
+				return next.Do(r)
+			})
+		}
+	}
+
+	client, err := newClientWithURL(ts.URL+"/api/v1", uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithMiddleware(audit("outer"), audit("inner")))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"outer", "inner"}, order)
+	assert.Equal(t, []string{"/account/balances", "/account/balances"}, endpoints)
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	fault := func(next Doer) Doer {
+		return DoerFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Status:     "503 Service Unavailable",
+				Header:     http.Header{},
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{"success":false,"message":"injected"}`))),
+				Request:    r,
+			}, nil
+		})
+	}
+
+	client, err := newClientWithURL("http://127.0.0.1:1", uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithMiddleware(fault))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	_, err = client.GetMarkets()
+	apiErr, ok := err.(*APIError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, "injected", apiErr.Message)
+}
+
+func TestEndpointName(t *testing.T) {
+	request, _ := http.NewRequest("GET", "https://api.p2pb2b.io/api/v1/public/book?market=ETH_BTC", nil)
+	assert.Equal(t, "", EndpointName(request))
+	assert.Equal(t, "/public/book", EndpointName(withEndpointName(request, baseAPI)))
+	assert.Equal(t, "/api/v1/public/book", EndpointName(withEndpointName(request, "https://api.p2pb2b.io")))
+}
@@ -0,0 +1,101 @@
+package p2pb2b
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NonceSource generates the nonces of signed requests. Every call to NextNonce
+// must return a value strictly greater than all values returned before for the
+// same API key.
+type NonceSource interface {
+	NextNonce() (int64, error)
+}
+
+type timeNonceSource struct {
+	mu   sync.Mutex
+	last int64
+	now  func() time.Time
+}
+
+// NewTimeNonceSource returns an in-memory NonceSource based on the current time in
+// milliseconds. It is safe for concurrent use but only guarantees monotonicity within
+// the process; clients of several processes sharing an API key should use
+// NewFileNonceSource instead.
+func NewTimeNonceSource() NonceSource {
+	return &timeNonceSource{now: time.Now}
+}
+
+func (s *timeNonceSource) NextNonce() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = nextNonce(s.last, s.now())
+	return s.last, nil
+}
+
+type fileNonceSource struct {
+	mu   sync.Mutex
+	path string
+	now  func() time.Time
+}
+
+// NewFileNonceSource returns a NonceSource which persists the last nonce in the file at
+// path. The file is locked while a nonce is generated, so several processes sharing the
+// same file never produce the same or a smaller nonce.
+func NewFileNonceSource(path string) NonceSource {
+	return &fileNonceSource{path: path, now: time.Now}
+}
+
+func (s *fileNonceSource) NextNonce() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("error opening nonce file, %v", err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return 0, fmt.Errorf("error locking nonce file, %v", err)
+	}
+	defer unlockFile(f)
+
+	content, err := ioutil.ReadAll(f)
+	if err != nil {
+		return 0, fmt.Errorf("error reading nonce file, %v", err)
+	}
+	var last int64
+	if trimmed := strings.TrimSpace(string(content)); trimmed != "" {
+		last, err = strconv.ParseInt(trimmed, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid nonce in file %s, %v", s.path, err)
+		}
+	}
+
+	next := nextNonce(last, s.now())
+	if err := f.Truncate(0); err != nil {
+		return 0, fmt.Errorf("error writing nonce file, %v", err)
+	}
+	if _, err := f.WriteAt([]byte(strconv.FormatInt(next, 10)), 0); err != nil {
+		return 0, fmt.Errorf("error writing nonce file, %v", err)
+	}
+	if err := f.Sync(); err != nil {
+		return 0, fmt.Errorf("error writing nonce file, %v", err)
+	}
+	return next, nil
+}
+
+// nextNonce returns the current time in milliseconds, or last+1 if that is not greater than last
+func nextNonce(last int64, now time.Time) int64 {
+	next := now.UnixNano() / int64(time.Millisecond)
+	if next <= last {
+		next = last + 1
+	}
+	return next
+}
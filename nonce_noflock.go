@@ -0,0 +1,22 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package p2pb2b
+
+import (
+	"os"
+	"sync"
+)
+
+// on platforms without flock the nonce file is only guarded within the process
+var nonceFileMu sync.Mutex
+
+func lockFile(f *os.File) error {
+	nonceFileMu.Lock()
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	nonceFileMu.Unlock()
+	return nil
+}
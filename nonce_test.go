@@ -0,0 +1,86 @@
+package p2pb2b
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextNonce(t *testing.T) {
+	now := time.Unix(1574197772, 500*int64(time.Millisecond))
+
+	assert.Equal(t, int64(1574197772500), nextNonce(0, now))
+	assert.Equal(t, int64(1574197772501), nextNonce(1574197772500, now))
+	assert.Equal(t, int64(1574197772601), nextNonce(1574197772600, now))
+}
+
+func TestTimeNonceSourceIsStrictlyMonotonic(t *testing.T) {
+	nonces := NewTimeNonceSource()
+
+	var mu sync.Mutex
+	seen := map[int64]bool{}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := int64(0)
+			for j := 0; j < 100; j++ {
+				nonce, err := nonces.NextNonce()
+				assert.Nil(t, err)
+				assert.True(t, nonce > last)
+				last = nonce
+
+				mu.Lock()
+				assert.False(t, seen[nonce], "nonce %d generated twice", nonce)
+				seen[nonce] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1000, len(seen))
+}
+
+func TestFileNonceSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "p2pb2b-nonce")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "nonce")
+
+	// a nonce in the future must be continued by every source using the file
+	future := time.Now().Add(time.Hour).UnixNano() / int64(time.Millisecond)
+	assert.Nil(t, ioutil.WriteFile(path, []byte(strconv.FormatInt(future, 10)), 0600))
+
+	first := NewFileNonceSource(path)
+	second := NewFileNonceSource(path)
+
+	nonce, err := first.NextNonce()
+	assert.Nil(t, err)
+	assert.Equal(t, future+1, nonce)
+
+	nonce, err = second.NextNonce()
+	assert.Nil(t, err)
+	assert.Equal(t, future+2, nonce)
+
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, strconv.FormatInt(future+2, 10), string(content))
+}
+
+func TestFileNonceSourceInvalidContent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "p2pb2b-nonce")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "nonce")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("blubb"), 0600))
+
+	_, err = NewFileNonceSource(path).NextNonce()
+	assert.NotNil(t, err)
+}
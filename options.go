@@ -0,0 +1,302 @@
+package p2pb2b
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Option configures the client created by NewClient
+type Option func(*client) error
+
+// WithHTTPClient sets the http.Client used for all requests. The given client is
+// not modified, WithTimeout and WithTransport are applied to a copy of it.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) error {
+		if httpClient == nil {
+			return fmt.Errorf("http client must not be nil")
+		}
+		c.http = httpClient
+		return nil
+	}
+}
+
+// WithBaseURL sets the API base URL, e.g. a staging or mock server. Defaults to baseAPI.
+func WithBaseURL(baseURL string) Option {
+	return func(c *client) error {
+		u, err := parseBaseURL(baseURL)
+		if err != nil {
+			return err
+		}
+		c.url = u
+		c.endpoints = nil
+		return nil
+	}
+}
+
+// WithTimeout sets the overall timeout of a single HTTP request. Zero means no timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *client) error {
+		if timeout < 0 {
+			return fmt.Errorf("timeout must not be < 0")
+		}
+		c.timeout = &timeout
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *client) error {
+		c.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithTransport sets the http.RoundTripper used for all requests, e.g. a shared pooled transport
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *client) error {
+		if transport == nil {
+			return fmt.Errorf("transport must not be nil")
+		}
+		c.transport = transport
+		return nil
+	}
+}
+
+// WithNonceSource sets the NonceSource used for signed requests without an explicit
+// nonce. Defaults to NewTimeNonceSource().
+func WithNonceSource(nonces NonceSource) Option {
+	return func(c *client) error {
+		if nonces == nil {
+			return fmt.Errorf("nonce source must not be nil")
+		}
+		c.nonces = nonces
+		return nil
+	}
+}
+
+// WithRetryPolicy enables retries of public endpoints and idempotent private queries
+// with the given policy, e.g. DefaultRetryPolicy(). By default requests are not retried.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *client) error {
+		if policy.InitialBackoff < 0 || policy.MaxBackoff < 0 {
+			return fmt.Errorf("retry backoff must not be < 0")
+		}
+		if policy.Jitter < 0 || policy.Jitter > 1 {
+			return fmt.Errorf("retry jitter must be between 0 and 1")
+		}
+		c.retry = policy
+		return nil
+	}
+}
+
+// WithOrderReconciler sets the OrderReconciler called when CreateOrder fails with an unknown outcome
+func WithOrderReconciler(reconciler OrderReconciler) Option {
+	return func(c *client) error {
+		c.orderReconciler = reconciler
+		return nil
+	}
+}
+
+// WithFailover sends requests to several base URLs with health tracking, replacing
+// WithBaseURL. Public requests fail over to the next healthy base URL on transport errors
+// and 5xx responses. Signed requests stick to one base URL while it is healthy and are
+// never resent to another one, so a request that might have been executed is not duplicated.
+func WithFailover(config FailoverConfig) Option {
+	return func(c *client) error {
+		pool, err := newEndpointPool(config)
+		if err != nil {
+			return err
+		}
+		c.endpoints = pool
+		c.url = pool.urls[0].url
+		return nil
+	}
+}
+
+// WithRateLimiter enables client side rate limiting with separate token buckets for
+// public and private endpoints, shared by all goroutines using the client
+func WithRateLimiter(config RateLimiterConfig) Option {
+	return func(c *client) error {
+		limiter, err := newRateLimiter(config)
+		if err != nil {
+			return err
+		}
+		c.limiter = limiter
+		return nil
+	}
+}
+
+// WithCircuitBreaker enables separate circuit breakers for public and trading endpoints.
+// While a breaker is open its requests fail fast with a CircuitOpenError.
+func WithCircuitBreaker(config CircuitBreakerConfig) Option {
+	return func(c *client) error {
+		breakers, err := newCircuitBreakers(config)
+		if err != nil {
+			return err
+		}
+		c.breakers = breakers
+		return nil
+	}
+}
+
+// WithLogger sets the Logger used for request and response logging. Credentials and
+// signatures are redacted. Defaults to a logger discarding everything.
+func WithLogger(logger Logger) Option {
+	return func(c *client) error {
+		if logger == nil {
+			return fmt.Errorf("logger must not be nil")
+		}
+		c.logger = logger
+		return nil
+	}
+}
+
+// WithMiddleware adds middlewares around the HTTP transport. Middlewares see every
+// fully signed request; the first given middleware is the outermost one.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *client) error {
+		for _, m := range middlewares {
+			if m == nil {
+				return fmt.Errorf("middleware must not be nil")
+			}
+		}
+		c.middlewares = append(c.middlewares, middlewares...)
+		return nil
+	}
+}
+
+// WithMetrics sets a MetricsRecorder receiving the latency and status of every request
+// and the class of every APIError, e.g. the Prometheus compatible NewMetrics()
+func WithMetrics(metrics MetricsRecorder) Option {
+	return func(c *client) error {
+		c.metrics = metrics
+		return nil
+	}
+}
+
+// WithResponseCache caches successful responses of public endpoints in cache, see ResponseCache
+func WithResponseCache(cache *ResponseCache) Option {
+	return func(c *client) error {
+		c.cache = cache
+		return nil
+	}
+}
+
+// WithRequestCoalescer lets concurrent identical public requests share one HTTP request,
+// see RequestCoalescer
+func WithRequestCoalescer(coalescer *RequestCoalescer) Option {
+	return func(c *client) error {
+		c.coalescer = coalescer
+		return nil
+	}
+}
+
+// WithDryRun builds and signs CreateOrder and CancelOrder requests as usual, but passes
+// them to hook instead of sending them and returns synthetic successful responses. Orders
+// get negative IDs. Public and read-only account requests are sent as usual. hook may be
+// nil, dry-run requests are also logged at Info level. They pass the middlewares, but use
+// no tokens of the rate limiter and are not counted by the circuit breaker and metrics.
+func WithDryRun(hook DryRunHook) Option {
+	return func(c *client) error {
+		c.dryRun = &dryRun{hook: hook, now: time.Now}
+		return nil
+	}
+}
+
+// WithSignatureAlgorithm sets the HMAC algorithm private requests are signed with, defaults to HMACSHA256
+func WithSignatureAlgorithm(algorithm SignatureAlgorithm) Option {
+	return func(c *client) error {
+		if _, err := algorithm.hash(); err != nil {
+			return err
+		}
+		c.signatureAlgorithm = algorithm
+		return nil
+	}
+}
+
+// WithSigner sets a custom Signer for private requests, replacing the HMAC signature with the API secret
+func WithSigner(signer Signer) Option {
+	return func(c *client) error {
+		if signer == nil {
+			return fmt.Errorf("signer must not be nil")
+		}
+		c.signer = signer
+		return nil
+	}
+}
+
+// WithCredentialsProvider sets the provider of API key and secret, which is asked for
+// the current credentials before every request
+func WithCredentialsProvider(provider CredentialsProvider) Option {
+	return func(c *client) error {
+		if provider == nil {
+			return fmt.Errorf("credentials provider must not be nil")
+		}
+		c.credentials = provider
+		return nil
+	}
+}
+
+// WithMaxResponseSize sets the maximum size of a response body in bytes, larger responses
+// fail with ErrResponseTooLarge. Defaults to DefaultMaxResponseSize, 0 disables the limit.
+func WithMaxResponseSize(size int64) Option {
+	return func(c *client) error {
+		if size < 0 {
+			return fmt.Errorf("max response size must not be < 0")
+		}
+		c.maxResponseSize = size
+		return nil
+	}
+}
+
+// WithServerClock synchronizes clock with the current_time of every public response.
+// The default nonce source then generates nonces from the server time, so hosts with a
+// drifting clock are not rejected. A clock can be shared by several clients.
+func WithServerClock(clock *ServerClock) Option {
+	return func(c *client) error {
+		c.clock = clock
+		return nil
+	}
+}
+
+func defaultHTTPClient() *http.Client {
+	return &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// applyOptions applies all options to c and finalizes the http client
+func (c *client) applyOptions(opts ...Option) error {
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		if err := opt(c); err != nil {
+			return err
+		}
+	}
+	if c.timeout != nil || c.transport != nil {
+		httpClient := *c.http
+		if c.timeout != nil {
+			httpClient.Timeout = *c.timeout
+		}
+		if c.transport != nil {
+			httpClient.Transport = c.transport
+		}
+		c.http = &httpClient
+	}
+	var doer Doer = c.http
+	if c.dryRun != nil {
+		c.dryRun.next = c.http
+		c.dryRun.logger = c.log
+		doer = c.dryRun
+	}
+	if len(c.middlewares) > 0 || c.dryRun != nil {
+		c.doer = chainMiddlewares(doer, c.middlewares)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package p2pb2b
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingTransport struct {
+	count int
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.count++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestNewClientOptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/public/symbols", r.URL.String())
+		assert.Equal(t, "go-p2pb2b-test/1.0", r.Header.Get("User-Agent"))
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":["ETH"]}`))
+	}))
+	defer ts.Close()
+
+	transport := &countingTransport{}
+	httpClient := &http.Client{}
+	p2pClient, err := NewClient(uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithHTTPClient(httpClient),
+		WithBaseURL(ts.URL+"/"),
+		WithTimeout(5*time.Second),
+		WithUserAgent("go-p2pb2b-test/1.0"),
+		WithTransport(transport),
+	)
+	assert.Nil(t, err)
+
+	resp, err := p2pClient.GetSymbols()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ETH"}, resp.Result)
+	assert.Equal(t, 1, transport.count)
+
+	// the passed http client must not be modified
+	assert.Equal(t, time.Duration(0), httpClient.Timeout)
+	assert.Nil(t, httpClient.Transport)
+
+	c := p2pClient.(*client)
+	assert.Equal(t, ts.URL, c.url)
+	assert.Equal(t, 5*time.Second, c.http.Timeout)
+}
+
+func TestNewClientInvalidOptions(t *testing.T) {
+	_, err := NewClient("key", "secret", WithBaseURL("not a url"))
+	assert.NotNil(t, err)
+
+	_, err = NewClient("key", "secret", WithTimeout(-time.Second))
+	assert.NotNil(t, err)
+
+	_, err = NewClient("key", "secret", WithHTTPClient(nil))
+	assert.NotNil(t, err)
+
+	_, err = NewClient("key", "secret", WithTransport(nil))
+	assert.NotNil(t, err)
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	p2pClient, err := NewClient("key", "secret")
+	assert.Nil(t, err)
+
+	c := p2pClient.(*client)
+	assert.Equal(t, baseAPI, c.url)
+	assert.Equal(t, time.Duration(0), c.http.Timeout)
+	assert.NotNil(t, c.http.CheckRedirect)
+}
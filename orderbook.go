@@ -1,10 +1,9 @@
 package p2pb2b
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"net/url"
 )
 
 type OrderBookResp struct {
@@ -23,21 +22,25 @@ type OrderBook struct {
 
 type OrderBookEntry struct {
 	ID        int     `json:"id"`
-	Left      float64 `json:"left,string"`
+	Left      Decimal `json:"left"`
 	Market    string  `json:"market"`
-	Amount    float64 `json:"amount,string"`
+	Amount    Decimal `json:"amount"`
 	Type      string  `json:"type"`
-	Price     float64 `json:"price,string"`
+	Price     Decimal `json:"price"`
 	Timestamp float64 `json:"timestamp"`
 	Side      string  `json:"side"`
-	DealFee   float64 `json:"dealFee,string"`
-	TakerFee  float64 `json:"takerFee,string"`
-	MakerFee  float64 `json:"makerFee,string"`
-	DealStock float64 `json:"dealStock,string"`
-	DealMoney float64 `json:"dealMoney,string"`
+	DealFee   Decimal `json:"dealFee"`
+	TakerFee  Decimal `json:"takerFee"`
+	MakerFee  Decimal `json:"makerFee"`
+	DealStock Decimal `json:"dealStock"`
+	DealMoney Decimal `json:"dealMoney"`
 }
 
 func (c *client) GetOrderBook(market string, side string, offset int64, limit int64) (*OrderBookResp, error) {
+	return c.GetOrderBookCtx(context.Background(), market, side, offset, limit)
+}
+
+func (c *client) GetOrderBookCtx(ctx context.Context, market string, side string, offset int64, limit int64) (*OrderBookResp, error) {
 	if market == "" {
 		return nil, fmt.Errorf("parameter market must not be empty")
 	}
@@ -51,25 +54,10 @@ func (c *client) GetOrderBook(market string, side string, offset int64, limit in
 		return nil, fmt.Errorf("parameter limit must not be <= 0")
 	}
 
-	url := fmt.Sprintf("%s/public/book?market=%s&side=%s&offset=%d&limit=%d", c.url, market, side, offset, limit)
-
-	resp, err := c.sendGet(url, nil)
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	query := fmt.Sprintf("market=%s&side=%s&offset=%d&limit=%d", url.QueryEscape(market), url.QueryEscape(side), offset, limit)
 
 	var result OrderBookResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, orderBookEndpoint, query, nil, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -92,32 +92,32 @@ func TestGetOrderBook(t *testing.T) {
 	assert.Equal(t, 2, len(resp.Result.Orders))
 
 	assert.Equal(t, 1456837208, resp.Result.Orders[0].ID)
-	assert.Equal(t, 1.7, resp.Result.Orders[0].Left)
+	assert.Equal(t, "1.7", resp.Result.Orders[0].Left.String())
 	assert.Equal(t, "ETH_BTC", resp.Result.Orders[0].Market)
-	assert.Equal(t, 1.7, resp.Result.Orders[0].Amount)
+	assert.Equal(t, "1.7", resp.Result.Orders[0].Amount.String())
 	assert.Equal(t, "limit", resp.Result.Orders[0].Type)
-	assert.Equal(t, 0.021443, resp.Result.Orders[0].Price)
+	assert.Equal(t, "0.021443", resp.Result.Orders[0].Price.String())
 	assert.Equal(t, 1574195955.326248, resp.Result.Orders[0].Timestamp)
 	assert.Equal(t, "sell", resp.Result.Orders[0].Side)
-	assert.Equal(t, 0.000080124, resp.Result.Orders[0].DealFee)
-	assert.Equal(t, 0.0001, resp.Result.Orders[0].TakerFee)
-	assert.Equal(t, 0.0001, resp.Result.Orders[0].MakerFee)
-	assert.Equal(t, 1.821, resp.Result.Orders[0].DealStock)
-	assert.Equal(t, 0.040062, resp.Result.Orders[0].DealMoney)
+	assert.Equal(t, "0.000080124", resp.Result.Orders[0].DealFee.String())
+	assert.Equal(t, "0.0001", resp.Result.Orders[0].TakerFee.String())
+	assert.Equal(t, "0.0001", resp.Result.Orders[0].MakerFee.String())
+	assert.Equal(t, "1.821", resp.Result.Orders[0].DealStock.String())
+	assert.Equal(t, "0.040062", resp.Result.Orders[0].DealMoney.String())
 
 	assert.Equal(t, 1456837207, resp.Result.Orders[1].ID)
-	assert.Equal(t, 2.986, resp.Result.Orders[1].Left)
+	assert.Equal(t, "2.986", resp.Result.Orders[1].Left.String())
 	assert.Equal(t, "ETH_BTC", resp.Result.Orders[1].Market)
-	assert.Equal(t, 2.986, resp.Result.Orders[1].Amount)
+	assert.Equal(t, "2.986", resp.Result.Orders[1].Amount.String())
 	assert.Equal(t, "limit", resp.Result.Orders[1].Type)
-	assert.Equal(t, 0.021446, resp.Result.Orders[1].Price)
+	assert.Equal(t, "0.021446", resp.Result.Orders[1].Price.String())
 	assert.Equal(t, 1574195955.322718, resp.Result.Orders[1].Timestamp)
 	assert.Equal(t, "sell", resp.Result.Orders[1].Side)
-	assert.Equal(t, 0.000080124, resp.Result.Orders[1].DealFee)
-	assert.Equal(t, 0.0001, resp.Result.Orders[1].TakerFee)
-	assert.Equal(t, 0.0001, resp.Result.Orders[1].MakerFee)
-	assert.Equal(t, 1.821, resp.Result.Orders[1].DealStock)
-	assert.Equal(t, 0.040062, resp.Result.Orders[1].DealMoney)
+	assert.Equal(t, "0.000080124", resp.Result.Orders[1].DealFee.String())
+	assert.Equal(t, "0.0001", resp.Result.Orders[1].TakerFee.String())
+	assert.Equal(t, "0.0001", resp.Result.Orders[1].MakerFee.String())
+	assert.Equal(t, "1.821", resp.Result.Orders[1].DealStock.String())
+	assert.Equal(t, "0.040062", resp.Result.Orders[1].DealMoney.String())
 }
 
 func TestGetOrderBookNegative(t *testing.T) {
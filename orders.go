@@ -1,31 +1,29 @@
 package p2pb2b
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
+	"context"
 )
 
 type CreateOrderResp struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 	Result  Order  `json:"result"`
+
+	meta *ResponseMeta
 }
 
 type Order struct {
-	Amount    float64 `json:"amount,string"`
-	DealFee   float64 `json:"dealFee,string"`
-	DealMoney float64 `json:"dealMoney,string"`
-	DealStock float64 `json:"dealStock,string"`
-	Left      float64 `json:"left,string"`
-	MakerFee  float64 `json:"makerFee,string"`
+	Amount    Decimal `json:"amount"`
+	DealFee   Decimal `json:"dealFee"`
+	DealMoney Decimal `json:"dealMoney"`
+	DealStock Decimal `json:"dealStock"`
+	Left      Decimal `json:"left"`
+	MakerFee  Decimal `json:"makerFee"`
 	Market    string  `json:"market"`
 	OrderID   int64   `json:"orderId"`
-	Price     float64 `json:"price,string"`
+	Price     Decimal `json:"price"`
 	Side      string  `json:"side"`
-	TakerFee  float64 `json:"takerFee,string"`
+	TakerFee  Decimal `json:"takerFee"`
 	Timestamp float64 `json:"timestamp"`
 	Type      string  `json:"type"`
 }
@@ -34,14 +32,16 @@ type CreateOrderRequest struct {
 	Request
 	Market string  `json:"market"`
 	Side   string  `json:"side"`
-	Amount float64 `json:"amount,string"`
-	Price  float64 `json:"price,string"`
+	Amount Decimal `json:"amount"`
+	Price  Decimal `json:"price"`
 }
 
 type CancelOrderResp struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 	Result  Order  `json:"result"`
+
+	meta *ResponseMeta
 }
 
 type CancelOrderRequest struct {
@@ -61,6 +61,8 @@ type QueryUnexecutedResp struct {
 	Success bool                  `json:"success"`
 	Message string                `json:"message"`
 	Result  QueryUnexecutedResult `json:"result"`
+
+	meta *ResponseMeta
 }
 
 type QueryUnexecutedResult struct {
@@ -71,17 +73,17 @@ type QueryUnexecutedResult struct {
 }
 
 type UnexecutedOrder struct {
-	Amount    float64 `json:"amount,string"`
-	DealFee   float64 `json:"dealFee,string"`
-	DealMoney float64 `json:"dealMoney,string"`
-	DealStock float64 `json:"dealStock,string"`
-	Left      float64 `json:"left,string"`
-	MakerFee  float64 `json:"makerFee,string"`
+	Amount    Decimal `json:"amount"`
+	DealFee   Decimal `json:"dealFee"`
+	DealMoney Decimal `json:"dealMoney"`
+	DealStock Decimal `json:"dealStock"`
+	Left      Decimal `json:"left"`
+	MakerFee  Decimal `json:"makerFee"`
 	Market    string  `json:"market"`
 	ID        int64   `json:"id"`
-	Price     float64 `json:"price,string"`
+	Price     Decimal `json:"price"`
 	Side      string  `json:"side"`
-	TakerFee  float64 `json:"takerFee,string"`
+	TakerFee  Decimal `json:"takerFee"`
 	Timestamp float64 `json:"timestamp"`
 	Type      string  `json:"type"`
 }
@@ -98,20 +100,20 @@ type QueryExecutedResp struct {
 }
 
 type AltOrder struct {
-	Amount     float64 `json:"amount,string"`
-	Price      float64 `json:"price,string"`
+	Amount     Decimal `json:"amount"`
+	Price      Decimal `json:"price"`
 	Type       string  `json:"type"`
 	ID         int64   `json:"id"`
 	Source     string  `json:"source,omitempty"`
 	Side       string  `json:"side"`
 	Ctime      float64 `json:"ctime"`
-	TakerFee   float64 `json:"takerFee,string"`
+	TakerFee   Decimal `json:"takerFee"`
 	Ftime      float64 `json:"ftime"`
 	Market     string  `json:"market"`
-	MakerFee   float64 `json:"makerFee,string"`
-	DealFee    float64 `json:"dealFee,string"`
-	DealStock  float64 `json:"dealStock,string"`
-	DealMoney  float64 `json:"dealMoney,string"`
+	MakerFee   Decimal `json:"makerFee"`
+	DealFee    Decimal `json:"dealFee"`
+	DealStock  Decimal `json:"dealStock"`
+	DealMoney  Decimal `json:"dealMoney"`
 	MarketName string  `json:"marketName"`
 }
 
@@ -135,150 +137,79 @@ type QueryDealsResult struct {
 
 type Record struct {
 	Time        float64 `json:"time"`
-	Fee         float64 `json:"fee,string"`
-	Price       float64 `json:"price,string"`
-	Amount      float64 `json:"amount,string"`
+	Fee         Decimal `json:"fee"`
+	Price       Decimal `json:"price"`
+	Amount      Decimal `json:"amount"`
 	ID          int64   `json:"id"`
 	DealOrderID int64   `json:"dealOrderId"`
 	Role        int64   `json:"role"`
-	Deal        float64 `json:"deal,string"`
+	Deal        Decimal `json:"deal"`
 }
 
 func (c *client) CreateOrder(request *CreateOrderRequest) (*CreateOrderResp, error) {
-	url := fmt.Sprintf("%s/order/new", c.url)
-	asJSON, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.sendPost(url, nil, bytes.NewReader(asJSON))
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
+	return c.CreateOrderCtx(context.Background(), request)
+}
 
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+func (c *client) CreateOrderCtx(ctx context.Context, request *CreateOrderRequest) (*CreateOrderResp, error) {
+	resp, err := c.createOrder(ctx, request)
+	if err != nil && c.orderReconciler != nil && isOutcomeUnknown(err) {
+		return c.orderReconciler(ctx, request, err)
 	}
+	return resp, err
+}
 
+// createOrder is never retried, a failed attempt may still have created the order
+func (c *client) createOrder(ctx context.Context, request *CreateOrderRequest) (*CreateOrderResp, error) {
 	var result CreateOrderResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, createOrderEndpoint, "", request, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
 func (c *client) CancelOrder(request *CancelOrderRequest) (*CancelOrderResp, error) {
-	url := fmt.Sprintf("%s/order/cancel", c.url)
-	asJSON, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.sendPost(url, nil, bytes.NewReader(asJSON))
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return c.CancelOrderCtx(context.Background(), request)
+}
 
+func (c *client) CancelOrderCtx(ctx context.Context, request *CancelOrderRequest) (*CancelOrderResp, error) {
 	var result CancelOrderResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, cancelOrderEndpoint, "", request, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
 func (c *client) QueryUnexecuted(request *QueryUnexecutedRequest) (*QueryUnexecutedResp, error) {
-	url := fmt.Sprintf("%s/orders", c.url)
-	asJSON, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.sendPost(url, nil, bytes.NewReader(asJSON))
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return c.QueryUnexecutedCtx(context.Background(), request)
+}
 
+func (c *client) QueryUnexecutedCtx(ctx context.Context, request *QueryUnexecutedRequest) (*QueryUnexecutedResp, error) {
 	var result QueryUnexecutedResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, unexecutedEndpoint, "", request, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
 func (c *client) QueryExecuted(request *QueryExecutedRequest) (*QueryExecutedResp, error) {
-	url := fmt.Sprintf("%s/account/order_history", c.url)
-	asJSON, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.sendPost(url, nil, bytes.NewReader(asJSON))
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return c.QueryExecutedCtx(context.Background(), request)
+}
 
+func (c *client) QueryExecutedCtx(ctx context.Context, request *QueryExecutedRequest) (*QueryExecutedResp, error) {
 	var result QueryExecutedResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, executedEndpoint, "", request, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
 func (c *client) QueryDeals(request *QueryDealsRequest) (*QueryDealsResp, error) {
-	url := fmt.Sprintf("%s/account/order", c.url)
-	asJSON, err := json.Marshal(request)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.sendPost(url, nil, bytes.NewReader(asJSON))
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return c.QueryDealsCtx(context.Background(), request)
+}
 
+func (c *client) QueryDealsCtx(ctx context.Context, request *QueryDealsRequest) (*QueryDealsResp, error) {
 	var result QueryDealsResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, dealsEndpoint, "", request, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
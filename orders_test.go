@@ -73,8 +73,8 @@ func TestCreateOrder(t *testing.T) {
 		},
 		Market: "ETH_BTC",
 		Side:   "buy",
-		Amount: 0.001,
-		Price:  1000.0,
+		Amount: MustParseDecimal("0.001"),
+		Price:  MustParseDecimal("1000"),
 	}
 	resp, err := client.CreateOrder(request)
 
@@ -402,3 +402,55 @@ func TestQueryDeals(t *testing.T) {
 	equal, _ := isEqualJSON(body, string(respBytes))
 	assert.True(t, equal, fmt.Sprintf("%s is not equal to %s", body, string(respBytes)))
 }
+
+type fixedNonceSource struct {
+	nonce int64
+}
+
+func (s *fixedNonceSource) NextNonce() (int64, error) {
+	s.nonce++
+	return s.nonce, nil
+}
+
+func TestCreateOrderFillsRequestAndNonce(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expectedReqBody := `{
+			"market": "ETH_BTC",
+			"side": "buy",
+			"amount": "0.001",
+			"price": "1000",
+			"request": "/api/v1/order/new",
+			"nonce": "1574197772001"
+		}`
+		reqBody, _ := ioutil.ReadAll(r.Body)
+		equal, err := isEqualJSON(expectedReqBody, string(reqBody))
+		assert.Nil(t, err, err)
+		assert.True(t, equal, fmt.Sprintf("%s is not equal to %s", expectedReqBody, string(reqBody)))
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":{}}`))
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL+"/api/v1", uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithNonceSource(&fixedNonceSource{nonce: 1574197772000}))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	request := &CreateOrderRequest{
+		Market: "ETH_BTC",
+		Side:   "buy",
+		Amount: MustParseDecimal("0.001"),
+		Price:  MustParseDecimal("1000"),
+	}
+	resp, err := client.CreateOrder(request)
+	assert.Nil(t, err)
+	assert.True(t, resp.Success)
+
+	// the request of the caller is left untouched
+	assert.Empty(t, request.Request.Request)
+	assert.Empty(t, request.Nonce)
+
+	_, err = client.CreateOrder(nil)
+	assert.NotNil(t, err)
+}
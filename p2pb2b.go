@@ -2,8 +2,8 @@
 package p2pb2b
 
 import (
+	"context"
 	"math"
-	"net/http"
 	"time"
 )
 
@@ -11,52 +11,104 @@ import (
 const baseAPI = "https://api.p2pb2b.io/api/v1"
 
 // for testing purposes only
-func newClientWithURL(url string, apiKey string, apiSecret string) (Client, error) {
-	return &client{
-		http: &http.Client{
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		},
-		auth: &auth{
-			APIKey:    apiKey,
-			APISecret: apiSecret,
-		},
-		url: url,
-	}, nil
+func newClientWithURL(url string, apiKey string, apiSecret string, opts ...Option) (Client, error) {
+	return NewClient(apiKey, apiSecret, append([]Option{WithBaseURL(url)}, opts...)...)
 }
 
-// NewClient creates a new p2pb2b client with apiKey and apiSecret
-func NewClient(apiKey string, apiSecret string) (Client, error) {
-	return newClientWithURL(baseAPI, apiKey, apiSecret)
+// NewClient creates a new p2pb2b client with apiKey and apiSecret. The client can
+// be customized with options like WithBaseURL, WithHTTPClient or WithTimeout.
+// WithCredentialsProvider replaces apiKey and apiSecret. Private methods of a client
+// without API key and secret fail with ErrNoCredentials without calling the exchange.
+func NewClient(apiKey string, apiSecret string, opts ...Option) (Client, error) {
+	return newClient(StaticCredentials(apiKey, apiSecret), opts...)
 }
 
-// Client is the basic p2pb2b client interface
-type Client interface {
-	PostCurrencyBalance(request *AccountCurrencyBalanceRequest) (*AccountCurrencyBalanceResp, error)
-	PostBalances(request *AccountBalancesRequest) (*AccountBalancesResp, error)
-	CreateOrder(request *CreateOrderRequest) (*CreateOrderResp, error)
-	CancelOrder(request *CancelOrderRequest) (*CancelOrderResp, error)
-	QueryUnexecuted(request *QueryUnexecutedRequest) (*QueryUnexecutedResp, error)
-	QueryExecuted(request *QueryExecutedRequest) (*QueryExecutedResp, error)
-	QueryDeals(request *QueryDealsRequest) (*QueryDealsResp, error)
+// NewPublicClient creates a client for the public endpoints only, which never sends
+// an API key or signature. WithCredentialsProvider is ignored.
+func NewPublicClient(opts ...Option) (PublicClient, error) {
+	c, err := newClient(nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.credentials = nil
+	return c, nil
+}
+
+func newClient(credentials CredentialsProvider, opts ...Option) (*client, error) {
+	nonces := &timeNonceSource{now: time.Now}
+	c := &client{
+		http:        defaultHTTPClient(),
+		credentials: credentials,
+		url:         baseAPI,
+		nonces:      nonces,
+		logger:      noopLogger{},
+
+		maxResponseSize: DefaultMaxResponseSize,
+	}
+	if err := c.applyOptions(opts...); err != nil {
+		return nil, err
+	}
+	if c.clock != nil && c.nonces == NonceSource(nonces) {
+		nonces.now = c.clock.ServerTime
+	}
+	return c, nil
+}
+
+// PublicClient is the interface of the public market data endpoints, which need no
+// credentials. Every method has a Ctx variant taking a context.Context whose
+// cancellation and deadline are propagated into the HTTP request; the plain variants
+// use context.Background().
+type PublicClient interface {
 	GetMarkets() (*MarketsResp, error)
+	GetMarketsCtx(ctx context.Context) (*MarketsResp, error)
 	GetTickers() (*TickersResp, error)
+	GetTickersCtx(ctx context.Context) (*TickersResp, error)
 	GetTicker(market string) (*TickerResp, error)
+	GetTickerCtx(ctx context.Context, market string) (*TickerResp, error)
 	GetOrderBook(market string, side string, offset int64, limit int64) (*OrderBookResp, error)
+	GetOrderBookCtx(ctx context.Context, market string, side string, offset int64, limit int64) (*OrderBookResp, error)
 	GetHistory(market string, lastID int64, limit int64) (*HistoryResp, error)
+	GetHistoryCtx(ctx context.Context, market string, lastID int64, limit int64) (*HistoryResp, error)
 	GetDepthResult(market string, limit int64) (*DepthResultResp, error)
+	GetDepthResultCtx(ctx context.Context, market string, limit int64) (*DepthResultResp, error)
 	GetProducts() (*ProductsResp, error)
+	GetProductsCtx(ctx context.Context) (*ProductsResp, error)
 	GetSymbols() (*SymbolsResp, error)
+	GetSymbolsCtx(ctx context.Context) (*SymbolsResp, error)
+}
+
+// Client is the basic p2pb2b client interface, the public endpoints plus the signed
+// account and trading endpoints.
+type Client interface {
+	PublicClient
+	PostCurrencyBalance(request *AccountCurrencyBalanceRequest) (*AccountCurrencyBalanceResp, error)
+	PostCurrencyBalanceCtx(ctx context.Context, request *AccountCurrencyBalanceRequest) (*AccountCurrencyBalanceResp, error)
+	PostBalances(request *AccountBalancesRequest) (*AccountBalancesResp, error)
+	PostBalancesCtx(ctx context.Context, request *AccountBalancesRequest) (*AccountBalancesResp, error)
+	CreateOrder(request *CreateOrderRequest) (*CreateOrderResp, error)
+	CreateOrderCtx(ctx context.Context, request *CreateOrderRequest) (*CreateOrderResp, error)
+	CancelOrder(request *CancelOrderRequest) (*CancelOrderResp, error)
+	CancelOrderCtx(ctx context.Context, request *CancelOrderRequest) (*CancelOrderResp, error)
+	QueryUnexecuted(request *QueryUnexecutedRequest) (*QueryUnexecutedResp, error)
+	QueryUnexecutedCtx(ctx context.Context, request *QueryUnexecutedRequest) (*QueryUnexecutedResp, error)
+	QueryExecuted(request *QueryExecutedRequest) (*QueryExecutedResp, error)
+	QueryExecutedCtx(ctx context.Context, request *QueryExecutedRequest) (*QueryExecutedResp, error)
+	QueryDeals(request *QueryDealsRequest) (*QueryDealsResp, error)
+	QueryDealsCtx(ctx context.Context, request *QueryDealsRequest) (*QueryDealsResp, error)
 }
 
-// Response is the basic http response struct
+// Response is the basic http response struct. Its Meta method returns the raw HTTP
+// metadata of the response.
 type Response struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
+
+	meta *ResponseMeta
 }
 
-// Request is the basic http request struct
+// Request is the basic http request struct. Request and Nonce are filled by the
+// client if left empty: Request with the path of the called endpoint, e.g.
+// /api/v1/order/new, and Nonce with the next value of the client's NonceSource.
 type Request struct {
 	Request string `json:"request"`
 	Nonce   string `json:"nonce"`
@@ -2,7 +2,14 @@ package p2pb2b
 
 import (
 	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // isEqualJSON checks two json strings for equality
@@ -22,3 +29,60 @@ func isEqualJSON(s1 string, s2 string) (bool, error) {
 
 	return reflect.DeepEqual(o1, o2), nil
 }
+
+func TestNewPublicClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, hasKey := r.Header[http.CanonicalHeaderKey(HeaderXTxcAPIKey)]
+		assert.False(t, hasKey)
+		assert.Empty(t, r.Header.Get(HeaderXTxcSignature))
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":["ETH"]}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewPublicClient(WithBaseURL(ts.URL), WithCredentialsProvider(StaticCredentials("key", "secret")))
+	assert.Nil(t, err)
+
+	resp, err := client.GetSymbols()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ETH"}, resp.Result)
+
+	// private methods are not part of PublicClient, and fail fast if called anyway
+	_, isClient := client.(Client)
+	assert.True(t, isClient)
+	_, err = client.(Client).PostBalances(&AccountBalancesRequest{})
+	assert.True(t, errors.Is(err, ErrNoCredentials))
+}
+
+func TestClientWithoutCredentialsFailsFast(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, hasKey := r.Header[http.CanonicalHeaderKey(HeaderXTxcAPIKey)]
+		assert.False(t, hasKey)
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":[]}`))
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL, "", "")
+	assert.Nil(t, err)
+
+	_, err = client.GetMarkets()
+	assert.Nil(t, err)
+
+	_, err = client.CreateOrder(&CreateOrderRequest{Market: "ETH_BTC", Side: "buy", Amount: NewDecimalFromInt(1), Price: NewDecimalFromInt(1)})
+	assert.True(t, errors.Is(err, ErrNoCredentials))
+	assert.Contains(t, err.Error(), "/order/new")
+	_, err = client.QueryDeals(&QueryDealsRequest{OrderID: 1})
+	assert.True(t, errors.Is(err, ErrNoCredentials))
+
+	client, err = newClientWithURL(ts.URL, "key", "")
+	assert.Nil(t, err)
+	_, err = client.CancelOrder(&CancelOrderRequest{Market: "ETH_BTC", OrderID: 1})
+	assert.True(t, errors.Is(err, ErrNoCredentials))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
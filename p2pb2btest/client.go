@@ -0,0 +1,563 @@
+package p2pb2btest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	p2pb2b "github.com/krinklesaurus/go-p2pb2b"
+)
+
+// tickerPeriod is the period of the ticker statistics
+const tickerPeriod = 24 * time.Hour
+
+func (e *Exchange) GetMarkets() (*p2pb2b.MarketsResp, error) {
+	return e.GetMarketsCtx(context.Background())
+}
+
+func (e *Exchange) GetMarketsCtx(ctx context.Context) (*p2pb2b.MarketsResp, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	response, now := e.response()
+	result := &p2pb2b.MarketsResp{Response: response, Result: []p2pb2b.Market{}, CacheTime: now, CurrentTime: now}
+	for _, b := range e.markets {
+		result.Result = append(result.Result, p2pb2b.Market{
+			Name:      b.market.Name,
+			Stock:     b.market.Stock,
+			Money:     b.market.Money,
+			MoneyPrec: b.market.MoneyPrec,
+			StockPrec: b.market.StockPrec,
+			FeePrec:   b.market.FeePrec,
+			MinAmount: b.market.MinAmount,
+		})
+	}
+	return result, nil
+}
+
+func (e *Exchange) GetTickers() (*p2pb2b.TickersResp, error) {
+	return e.GetTickersCtx(context.Background())
+}
+
+func (e *Exchange) GetTickersCtx(ctx context.Context) (*p2pb2b.TickersResp, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	response, now := e.response()
+	result := &p2pb2b.TickersResp{Response: response, Result: map[string]p2pb2b.TickersResult{}, CacheTime: now, CurrentTime: now}
+	for _, b := range e.markets {
+		t := e.ticker(b)
+		result.Result[b.market.Name] = p2pb2b.TickersResult{
+			At: int(now),
+			Ticker: p2pb2b.TickersEntry{
+				Bid:    t.Bid,
+				Ask:    t.Ask,
+				Low:    t.Low,
+				High:   t.High,
+				Last:   t.Last,
+				Volume: t.Volume,
+				Change: t.Change,
+			},
+		}
+	}
+	return result, nil
+}
+
+func (e *Exchange) GetTicker(market string) (*p2pb2b.TickerResp, error) {
+	return e.GetTickerCtx(context.Background(), market)
+}
+
+func (e *Exchange) GetTickerCtx(ctx context.Context, market string) (*p2pb2b.TickerResp, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b, err := e.book("/api/v1/public/ticker", market)
+	if err != nil {
+		return nil, err
+	}
+	response, now := e.response()
+	return &p2pb2b.TickerResp{Response: response, Result: e.ticker(b), CacheTime: now, CurrentTime: now}, nil
+}
+
+// ticker returns the best prices of b and the statistics of its deals within tickerPeriod
+func (e *Exchange) ticker(b *book) p2pb2b.Ticker {
+	var t p2pb2b.Ticker
+	if len(b.bids) > 0 {
+		t.Bid = b.bids[0].price
+	}
+	if len(b.asks) > 0 {
+		t.Ask = b.asks[0].price
+	}
+	since := float64(e.now().Add(-tickerPeriod).UnixNano()) / 1e9
+	for _, d := range b.deals {
+		if d.time < since {
+			continue
+		}
+		if t.Open.IsZero() {
+			t.Open, t.High, t.Low = d.price, d.price, d.price
+		}
+		if d.price.GreaterThan(t.High) {
+			t.High = d.price
+		}
+		if d.price.LessThan(t.Low) {
+			t.Low = d.price
+		}
+		t.Last = d.price
+		t.Volume = t.Volume.Add(d.amount)
+		t.Deal = t.Deal.Add(d.money)
+	}
+	if !t.Open.IsZero() {
+		t.Change = t.Last.Sub(t.Open).Mul(p2pb2b.NewDecimalFromInt(100)).Div(t.Open, 2)
+	}
+	return t
+}
+
+func (e *Exchange) GetOrderBook(market string, side string, offset int64, limit int64) (*p2pb2b.OrderBookResp, error) {
+	return e.GetOrderBookCtx(context.Background(), market, side, offset, limit)
+}
+
+func (e *Exchange) GetOrderBookCtx(ctx context.Context, market string, side string, offset int64, limit int64) (*p2pb2b.OrderBookResp, error) {
+	if side != sideBuy && side != sideSell {
+		return nil, fmt.Errorf("parameter side must be buy or sell, got %s", side)
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("parameter offset must not be < 0")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("parameter limit must not be <= 0")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b, err := e.book("/api/v1/public/book", market)
+	if err != nil {
+		return nil, err
+	}
+	orders := b.side(side)
+	start, end := page(len(orders), offset, limit)
+	response, now := e.response()
+	result := &p2pb2b.OrderBookResp{
+		Response: response,
+		Result: p2pb2b.OrderBook{
+			Offset: int(offset),
+			Limit:  int(limit),
+			Total:  len(orders),
+			Orders: []p2pb2b.OrderBookEntry{},
+		},
+		CacheTime:   now,
+		CurrentTime: now,
+	}
+	for _, o := range orders[start:end] {
+		result.Result.Orders = append(result.Result.Orders, p2pb2b.OrderBookEntry{
+			ID:        int(o.id),
+			Left:      o.left,
+			Market:    o.market,
+			Amount:    o.amount,
+			Type:      "limit",
+			Price:     o.price,
+			Timestamp: o.ctime,
+			Side:      o.side,
+			DealFee:   o.dealFee,
+			TakerFee:  o.takerFee,
+			MakerFee:  o.makerFee,
+			DealStock: o.dealStock,
+			DealMoney: o.dealMoney,
+		})
+	}
+	return result, nil
+}
+
+func (e *Exchange) GetHistory(market string, lastID int64, limit int64) (*p2pb2b.HistoryResp, error) {
+	return e.GetHistoryCtx(context.Background(), market, lastID, limit)
+}
+
+// GetHistoryCtx returns the deals of market after the deal lastID, oldest first
+func (e *Exchange) GetHistoryCtx(ctx context.Context, market string, lastID int64, limit int64) (*p2pb2b.HistoryResp, error) {
+	if lastID < 0 {
+		return nil, fmt.Errorf("parameter offset must not be < 0")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("parameter limit must not be <= 0")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b, err := e.book("/api/v1/public/history", market)
+	if err != nil {
+		return nil, err
+	}
+	response, now := e.response()
+	result := &p2pb2b.HistoryResp{Response: response, Result: []p2pb2b.HistoryEntry{}, CacheTime: now, CurrentTime: now}
+	for _, d := range b.deals {
+		if d.id <= lastID {
+			continue
+		}
+		if int64(len(result.Result)) == limit {
+			break
+		}
+		result.Result = append(result.Result, p2pb2b.HistoryEntry{
+			ID:     int(d.id),
+			Type:   d.taker.side,
+			Time:   d.time,
+			Amount: d.amount,
+			Price:  d.price,
+		})
+	}
+	return result, nil
+}
+
+func (e *Exchange) GetDepthResult(market string, limit int64) (*p2pb2b.DepthResultResp, error) {
+	return e.GetDepthResultCtx(context.Background(), market, limit)
+}
+
+func (e *Exchange) GetDepthResultCtx(ctx context.Context, market string, limit int64) (*p2pb2b.DepthResultResp, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("parameter limit must not be <= 0")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b, err := e.book("/api/v1/public/depth/result", market)
+	if err != nil {
+		return nil, err
+	}
+	response, now := e.response()
+	return &p2pb2b.DepthResultResp{
+		Response: response,
+		Result: p2pb2b.DepthResultResult{
+			Asks: depth(b.asks, limit),
+			Bids: depth(b.bids, limit),
+		},
+		CacheTime:   now,
+		CurrentTime: now,
+	}, nil
+}
+
+// depth aggregates the left amounts of orders into at most limit price levels
+func depth(orders []*order, limit int64) []p2pb2b.DecimalPair {
+	levels := []p2pb2b.DecimalPair{}
+	for _, o := range orders {
+		n := len(levels)
+		if n > 0 && levels[n-1][0].Equal(o.price) {
+			levels[n-1][1] = levels[n-1][1].Add(o.left)
+			continue
+		}
+		if int64(n) == limit {
+			break
+		}
+		levels = append(levels, p2pb2b.DecimalPair{o.price, o.left})
+	}
+	return levels
+}
+
+func (e *Exchange) GetProducts() (*p2pb2b.ProductsResp, error) {
+	return e.GetProductsCtx(context.Background())
+}
+
+func (e *Exchange) GetProductsCtx(ctx context.Context) (*p2pb2b.ProductsResp, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	response, now := e.response()
+	result := &p2pb2b.ProductsResp{Response: response, Result: []p2pb2b.Product{}, CacheTime: now, CurrentTime: now}
+	for _, b := range e.markets {
+		result.Result = append(result.Result, p2pb2b.Product{
+			ID:         b.market.Name,
+			FromSymbol: b.market.Stock,
+			ToSymbol:   b.market.Money,
+		})
+	}
+	return result, nil
+}
+
+func (e *Exchange) GetSymbols() (*p2pb2b.SymbolsResp, error) {
+	return e.GetSymbolsCtx(context.Background())
+}
+
+func (e *Exchange) GetSymbolsCtx(ctx context.Context) (*p2pb2b.SymbolsResp, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	response, now := e.response()
+	result := &p2pb2b.SymbolsResp{Response: response, Result: []string{}, CacheTime: now, CurrentTime: now}
+	for _, b := range e.markets {
+		result.Result = append(result.Result, b.market.Name)
+	}
+	return result, nil
+}
+
+func (e *Exchange) PostCurrencyBalance(request *p2pb2b.AccountCurrencyBalanceRequest) (*p2pb2b.AccountCurrencyBalanceResp, error) {
+	return e.PostCurrencyBalanceCtx(context.Background(), request)
+}
+
+func (e *Exchange) PostCurrencyBalanceCtx(ctx context.Context, request *p2pb2b.AccountCurrencyBalanceRequest) (*p2pb2b.AccountCurrencyBalanceResp, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request must not be nil")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	balance := e.balance(request.Currency)
+	response, _ := e.response()
+	return &p2pb2b.AccountCurrencyBalanceResp{
+		Response: response,
+		Result: map[string]p2pb2b.AccountCurrencyBalance{
+			request.Currency: {Available: balance.Available, Freeze: balance.Freeze},
+		},
+	}, nil
+}
+
+func (e *Exchange) PostBalances(request *p2pb2b.AccountBalancesRequest) (*p2pb2b.AccountBalancesResp, error) {
+	return e.PostBalancesCtx(context.Background(), request)
+}
+
+func (e *Exchange) PostBalancesCtx(ctx context.Context, request *p2pb2b.AccountBalancesRequest) (*p2pb2b.AccountBalancesResp, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request must not be nil")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	response, _ := e.response()
+	result := &p2pb2b.AccountBalancesResp{Response: response, Result: map[string]p2pb2b.AccountBalance{}}
+	for currency, balance := range e.balances {
+		result.Result[currency] = *balance
+	}
+	return result, nil
+}
+
+func (e *Exchange) CreateOrder(request *p2pb2b.CreateOrderRequest) (*p2pb2b.CreateOrderResp, error) {
+	return e.CreateOrderCtx(context.Background(), request)
+}
+
+func (e *Exchange) CreateOrderCtx(ctx context.Context, request *p2pb2b.CreateOrderRequest) (*p2pb2b.CreateOrderResp, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request must not be nil")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o, err := e.placeOrder(request.Market, request.Side, request.Amount, request.Price, true)
+	if err != nil {
+		return nil, err
+	}
+	return &p2pb2b.CreateOrderResp{Success: true, Result: o.toOrder()}, nil
+}
+
+func (e *Exchange) CancelOrder(request *p2pb2b.CancelOrderRequest) (*p2pb2b.CancelOrderResp, error) {
+	return e.CancelOrderCtx(context.Background(), request)
+}
+
+func (e *Exchange) CancelOrderCtx(ctx context.Context, request *p2pb2b.CancelOrderRequest) (*p2pb2b.CancelOrderResp, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request must not be nil")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o, err := e.cancelOrder(request.Market, request.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	return &p2pb2b.CancelOrderResp{Success: true, Result: o.toOrder()}, nil
+}
+
+func (e *Exchange) QueryUnexecuted(request *p2pb2b.QueryUnexecutedRequest) (*p2pb2b.QueryUnexecutedResp, error) {
+	return e.QueryUnexecutedCtx(context.Background(), request)
+}
+
+// QueryUnexecutedCtx returns the open orders of the account in the market of request, newest first
+func (e *Exchange) QueryUnexecutedCtx(ctx context.Context, request *p2pb2b.QueryUnexecutedRequest) (*p2pb2b.QueryUnexecutedResp, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request must not be nil")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err := e.book("/api/v1/orders", request.Market); err != nil {
+		return nil, err
+	}
+	orders := e.ownOrders(func(o *order) bool { return o.open && o.market == request.Market })
+	start, end := page(len(orders), request.Offset, request.Limit)
+	result := &p2pb2b.QueryUnexecutedResp{
+		Success: true,
+		Result: p2pb2b.QueryUnexecutedResult{
+			Limit:  request.Limit,
+			Offset: request.Offset,
+			Total:  int64(len(orders)),
+			Result: []p2pb2b.UnexecutedOrder{},
+		},
+	}
+	for _, o := range orders[start:end] {
+		result.Result.Result = append(result.Result.Result, p2pb2b.UnexecutedOrder{
+			Amount:    o.amount,
+			DealFee:   o.dealFee,
+			DealMoney: o.dealMoney,
+			DealStock: o.dealStock,
+			Left:      o.left,
+			MakerFee:  o.makerFee,
+			Market:    o.market,
+			ID:        o.id,
+			Price:     o.price,
+			Side:      o.side,
+			TakerFee:  o.takerFee,
+			Timestamp: o.ctime,
+			Type:      "limit",
+		})
+	}
+	return result, nil
+}
+
+func (e *Exchange) QueryExecuted(request *p2pb2b.QueryExecutedRequest) (*p2pb2b.QueryExecutedResp, error) {
+	return e.QueryExecutedCtx(context.Background(), request)
+}
+
+// QueryExecutedCtx returns the closed orders of the account with at least one deal by
+// market, newest first. Offset and limit apply to each market.
+func (e *Exchange) QueryExecutedCtx(ctx context.Context, request *p2pb2b.QueryExecutedRequest) (*p2pb2b.QueryExecutedResp, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request must not be nil")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	response, _ := e.response()
+	result := &p2pb2b.QueryExecutedResp{Response: response, Result: map[string][]p2pb2b.AltOrder{}}
+	for _, b := range e.markets {
+		orders := e.ownOrders(func(o *order) bool { return !o.open && o.dealStock.Sign() > 0 && o.market == b.market.Name })
+		if len(orders) == 0 {
+			continue
+		}
+		start, end := page(len(orders), request.Offset, request.Limit)
+		executed := []p2pb2b.AltOrder{}
+		for _, o := range orders[start:end] {
+			executed = append(executed, p2pb2b.AltOrder{
+				Amount:     o.amount,
+				Price:      o.price,
+				Type:       "limit",
+				ID:         o.id,
+				Side:       o.side,
+				Ctime:      o.ctime,
+				TakerFee:   o.takerFee,
+				Ftime:      o.ftime,
+				Market:     o.market,
+				MakerFee:   o.makerFee,
+				DealFee:    o.dealFee,
+				DealStock:  o.dealStock,
+				DealMoney:  o.dealMoney,
+				MarketName: o.market,
+			})
+		}
+		result.Result[b.market.Name] = executed
+	}
+	return result, nil
+}
+
+func (e *Exchange) QueryDeals(request *p2pb2b.QueryDealsRequest) (*p2pb2b.QueryDealsResp, error) {
+	return e.QueryDealsCtx(context.Background(), request)
+}
+
+// QueryDealsCtx returns the deals of an order of the account, newest first
+func (e *Exchange) QueryDealsCtx(ctx context.Context, request *p2pb2b.QueryDealsRequest) (*p2pb2b.QueryDealsResp, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request must not be nil")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o, ok := e.orders[request.OrderID]
+	if !ok {
+		return nil, apiError("/api/v1/account/order", "Order not found.")
+	}
+	start, end := page(len(o.deals), request.Offset, request.Limit)
+	response, _ := e.response()
+	result := &p2pb2b.QueryDealsResp{
+		Response: response,
+		Result: p2pb2b.QueryDealsResult{
+			Offset:  request.Offset,
+			Limit:   request.Limit,
+			Records: []p2pb2b.Record{},
+		},
+	}
+	for i := len(o.deals) - 1 - start; i >= len(o.deals)-end; i-- {
+		d := o.deals[i]
+		record := p2pb2b.Record{
+			Time:        d.time,
+			Fee:         d.makerFee,
+			Price:       d.price,
+			Amount:      d.amount,
+			ID:          d.id,
+			DealOrderID: d.taker.id,
+			Role:        roleMaker,
+			Deal:        d.money,
+		}
+		if d.taker == o {
+			record.Fee = d.takerFee
+			record.DealOrderID = d.maker.id
+			record.Role = roleTaker
+		}
+		result.Result.Records = append(result.Result.Records, record)
+	}
+	return result, nil
+}
+
+// ownOrders returns the orders of the account matching filter, newest first
+func (e *Exchange) ownOrders(filter func(o *order) bool) []*order {
+	var orders []*order
+	for _, o := range e.orders {
+		if filter(o) {
+			orders = append(orders, o)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].id > orders[j].id })
+	return orders
+}
+
+func (o *order) toOrder() p2pb2b.Order {
+	return p2pb2b.Order{
+		Amount:    o.amount,
+		DealFee:   o.dealFee,
+		DealMoney: o.dealMoney,
+		DealStock: o.dealStock,
+		Left:      o.left,
+		MakerFee:  o.makerFee,
+		Market:    o.market,
+		OrderID:   o.id,
+		Price:     o.price,
+		Side:      o.side,
+		TakerFee:  o.takerFee,
+		Timestamp: o.ctime,
+		Type:      "limit",
+	}
+}
@@ -0,0 +1,270 @@
+package p2pb2btest
+
+import (
+	"fmt"
+	"sort"
+
+	p2pb2b "github.com/krinklesaurus/go-p2pb2b"
+)
+
+const (
+	roleMaker = 1
+	roleTaker = 2
+)
+
+// book is the state of a market: open orders by priority and all deals
+type book struct {
+	market Market
+	// asks are sorted by ascending, bids by descending price, both then by ID
+	asks  []*order
+	bids  []*order
+	deals []*deal
+}
+
+type order struct {
+	id       int64
+	market   string
+	side     string
+	amount   p2pb2b.Decimal
+	price    p2pb2b.Decimal
+	left     p2pb2b.Decimal
+	makerFee p2pb2b.Decimal
+	takerFee p2pb2b.Decimal
+	ctime    float64
+	ftime    float64
+	own      bool
+	open     bool
+
+	dealStock p2pb2b.Decimal
+	dealMoney p2pb2b.Decimal
+	dealFee   p2pb2b.Decimal
+	// frozen is the part of the account balance still frozen for the order
+	frozen p2pb2b.Decimal
+	deals  []*deal
+}
+
+type deal struct {
+	id       int64
+	time     float64
+	price    p2pb2b.Decimal
+	amount   p2pb2b.Decimal
+	money    p2pb2b.Decimal
+	maker    *order
+	taker    *order
+	makerFee p2pb2b.Decimal
+	takerFee p2pb2b.Decimal
+}
+
+// side returns the book side of orders of side, ordered by priority
+func (b *book) side(side string) []*order {
+	if side == sideBuy {
+		return b.bids
+	}
+	return b.asks
+}
+
+func (b *book) setSide(side string, orders []*order) {
+	if side == sideBuy {
+		b.bids = orders
+	} else {
+		b.asks = orders
+	}
+}
+
+// before reports whether a has priority over b on the same side of the book
+func before(a *order, b *order) bool {
+	if c := a.price.Cmp(b.price); c != 0 {
+		if a.side == sideBuy {
+			return c > 0
+		}
+		return c < 0
+	}
+	return a.id < b.id
+}
+
+func (b *book) insert(o *order) {
+	orders := b.side(o.side)
+	i := sort.Search(len(orders), func(i int) bool { return before(o, orders[i]) })
+	orders = append(orders, nil)
+	copy(orders[i+1:], orders[i:])
+	orders[i] = o
+	b.setSide(o.side, orders)
+}
+
+func (b *book) remove(o *order) {
+	orders := b.side(o.side)
+	for i, e := range orders {
+		if e == o {
+			b.setSide(o.side, append(orders[:i:i], orders[i+1:]...))
+			return
+		}
+	}
+}
+
+// crosses reports whether taker can trade with the resting order maker
+func crosses(taker *order, maker *order) bool {
+	if taker.side == sideBuy {
+		return maker.price.Cmp(taker.price) <= 0
+	}
+	return maker.price.Cmp(taker.price) >= 0
+}
+
+// placeOrder validates, funds and matches a new limit order, resting orders are
+// matched by best price first and then by age
+func (e *Exchange) placeOrder(market string, side string, amount p2pb2b.Decimal, price p2pb2b.Decimal, own bool) (*order, error) {
+	const endpoint = "/api/v1/order/new"
+	b, err := e.book(endpoint, market)
+	if err != nil {
+		return nil, err
+	}
+	m := b.market
+	if side != sideBuy && side != sideSell {
+		return nil, fmt.Errorf("parameter side must be buy or sell, got %s", side)
+	}
+	amount = amount.Round(int32(m.StockPrec))
+	price = price.Round(int32(m.MoneyPrec))
+	if amount.Sign() <= 0 || amount.LessThan(m.MinAmount) {
+		return nil, apiError(endpoint, fmt.Sprintf("Amount must be at least %s.", m.MinAmount))
+	}
+	if price.Sign() <= 0 {
+		return nil, apiError(endpoint, "Price must be greater than 0.")
+	}
+
+	e.lastID++
+	o := &order{
+		id:       e.lastID,
+		market:   market,
+		side:     side,
+		amount:   amount,
+		price:    price,
+		left:     amount,
+		makerFee: m.MakerFee,
+		takerFee: m.TakerFee,
+		ctime:    e.timestamp(),
+		own:      own,
+		open:     true,
+	}
+	if own {
+		currency, frozen := m.Stock, amount
+		if side == sideBuy {
+			currency, frozen = m.Money, amount.Mul(price).Round(int32(m.MoneyPrec))
+		}
+		balance := e.balance(currency)
+		if balance.Available.LessThan(frozen) {
+			e.lastID--
+			return nil, apiError(endpoint, fmt.Sprintf("Insufficient %s balance.", currency))
+		}
+		balance.Available = balance.Available.Sub(frozen)
+		balance.Freeze = balance.Freeze.Add(frozen)
+		o.frozen = frozen
+		e.orders[o.id] = o
+	}
+
+	opposite := sideSell
+	if side == sideSell {
+		opposite = sideBuy
+	}
+	for o.left.Sign() > 0 {
+		resting := b.side(opposite)
+		if len(resting) == 0 || !crosses(o, resting[0]) {
+			break
+		}
+		maker := resting[0]
+		e.trade(b, maker, o)
+		if maker.left.IsZero() {
+			b.remove(maker)
+			e.finish(b, maker)
+		}
+	}
+	if o.left.IsZero() {
+		e.finish(b, o)
+	} else {
+		b.insert(o)
+	}
+	return o, nil
+}
+
+// trade executes a deal between the resting order maker and taker at the price of maker
+func (e *Exchange) trade(b *book, maker *order, taker *order) {
+	m := b.market
+	amount := maker.left
+	if taker.left.LessThan(amount) {
+		amount = taker.left
+	}
+	e.lastDeal++
+	d := &deal{
+		id:     e.lastDeal,
+		time:   e.timestamp(),
+		price:  maker.price,
+		amount: amount,
+		money:  amount.Mul(maker.price).Round(int32(m.MoneyPrec)),
+		maker:  maker,
+		taker:  taker,
+	}
+	d.makerFee = e.fill(m, maker, d, maker.makerFee)
+	d.takerFee = e.fill(m, taker, d, taker.takerFee)
+	b.deals = append(b.deals, d)
+}
+
+// fill applies deal d to o, moving funds of the account for own orders, and returns the fee of o
+func (e *Exchange) fill(m Market, o *order, d *deal, feeRate p2pb2b.Decimal) p2pb2b.Decimal {
+	o.left = o.left.Sub(d.amount)
+	o.dealStock = o.dealStock.Add(d.amount)
+	o.dealMoney = o.dealMoney.Add(d.money)
+	o.deals = append(o.deals, d)
+
+	paid, spent, received, amount := m.Money, d.money, m.Stock, d.amount
+	if o.side == sideSell {
+		paid, spent, received, amount = m.Stock, d.amount, m.Money, d.money
+	}
+	fee := amount.Mul(feeRate).Round(int32(m.FeePrec))
+	o.dealFee = o.dealFee.Add(fee)
+	if o.own {
+		// deals are rounded separately, so they can add up to more than was frozen for
+		// the order; the excess is paid from the available balance
+		release := spent
+		if o.frozen.LessThan(release) {
+			release = o.frozen
+		}
+		balance := e.balance(paid)
+		balance.Freeze = balance.Freeze.Sub(release)
+		balance.Available = balance.Available.Sub(spent.Sub(release))
+		o.frozen = o.frozen.Sub(release)
+		available := e.balance(received)
+		available.Available = available.Available.Add(amount).Sub(fee)
+	}
+	return fee
+}
+
+// finish closes o, releasing the funds still frozen for it
+func (e *Exchange) finish(b *book, o *order) {
+	o.open = false
+	o.ftime = e.timestamp()
+	if !o.own || o.frozen.IsZero() {
+		return
+	}
+	currency := b.market.Stock
+	if o.side == sideBuy {
+		currency = b.market.Money
+	}
+	balance := e.balance(currency)
+	balance.Freeze = balance.Freeze.Sub(o.frozen)
+	balance.Available = balance.Available.Add(o.frozen)
+	o.frozen = p2pb2b.Decimal{}
+}
+
+// cancelOrder removes the open order id of the account from its market
+func (e *Exchange) cancelOrder(market string, id int64) (*order, error) {
+	const endpoint = "/api/v1/order/cancel"
+	b, err := e.book(endpoint, market)
+	if err != nil {
+		return nil, err
+	}
+	o, ok := e.orders[id]
+	if !ok || !o.open || o.market != market {
+		return nil, apiError(endpoint, "Order not found.")
+	}
+	b.remove(o)
+	e.finish(b, o)
+	return o, nil
+}
@@ -0,0 +1,211 @@
+// Package p2pb2btest provides an in-memory fake of the p2pb2b exchange for tests.
+//
+// An Exchange implements p2pb2b.Client. It keeps markets, balances and orders of a
+// single account in memory and matches orders with price-time priority, so the
+// private endpoints and the order book, depth, history and ticker endpoints all
+// report the same state:
+//
+//	exchange := p2pb2btest.NewExchange(p2pb2btest.Market{Name: "ETH_BTC", Stock: "ETH", Money: "BTC"})
+//	exchange.SetBalance("BTC", p2pb2b.MustParseDecimal("1"))
+//	exchange.AddOrder("ETH_BTC", "sell", p2pb2b.MustParseDecimal("2"), p2pb2b.MustParseDecimal("0.02")) // liquidity of another account
+//	resp, err := exchange.CreateOrder(&p2pb2b.CreateOrderRequest{
+//		Market: "ETH_BTC",
+//		Side:   "buy",
+//		Amount: p2pb2b.MustParseDecimal("1"),
+//		Price:  p2pb2b.MustParseDecimal("0.021"),
+//	})
+package p2pb2btest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	p2pb2b "github.com/krinklesaurus/go-p2pb2b"
+)
+
+// defaultPrec is the precision of markets configured without one
+const defaultPrec = 8
+
+// defaultLimit is the limit of private queries sent without one
+const defaultLimit = 50
+
+const (
+	sideBuy  = "buy"
+	sideSell = "sell"
+)
+
+// Market is the configuration of a market of the fake exchange
+type Market struct {
+	// Name is the name of the market, e.g. ETH_BTC
+	Name string
+	// Stock is the traded currency, e.g. ETH
+	Stock string
+	// Money is the currency prices are quoted in, e.g. BTC
+	Money string
+	// StockPrec and MoneyPrec are the decimal places of amounts and prices, 8 if 0
+	StockPrec int
+	MoneyPrec int
+	// FeePrec is the decimal places of fees, 8 if 0
+	FeePrec int
+	// MinAmount is the minimum amount of an order
+	MinAmount p2pb2b.Decimal
+	// MakerFee and TakerFee are the fee rates deducted from the received currency, e.g. 0.002
+	MakerFee p2pb2b.Decimal
+	TakerFee p2pb2b.Decimal
+}
+
+// Exchange is an in-memory fake of the p2pb2b exchange for a single account. It is safe
+// for concurrent use.
+type Exchange struct {
+	mu       sync.Mutex
+	markets  []*book
+	books    map[string]*book
+	balances map[string]*p2pb2b.AccountBalance
+	orders   map[int64]*order
+	lastID   int64
+	lastDeal int64
+	now      func() time.Time
+}
+
+var _ p2pb2b.Client = (*Exchange)(nil)
+
+// NewExchange creates an exchange with markets and zero balances. It panics if a market
+// is invalid, see AddMarket.
+func NewExchange(markets ...Market) *Exchange {
+	e := &Exchange{
+		books:    make(map[string]*book),
+		balances: make(map[string]*p2pb2b.AccountBalance),
+		orders:   make(map[int64]*order),
+		now:      time.Now,
+	}
+	for _, m := range markets {
+		if err := e.AddMarket(m); err != nil {
+			panic(err)
+		}
+	}
+	return e
+}
+
+// AddMarket adds market to the exchange. Name, Stock and Money must be set and Name
+// must be unique.
+func (e *Exchange) AddMarket(market Market) error {
+	if market.Name == "" || market.Stock == "" || market.Money == "" {
+		return fmt.Errorf("market needs a name, stock and money, got %+v", market)
+	}
+	if market.StockPrec == 0 {
+		market.StockPrec = defaultPrec
+	}
+	if market.MoneyPrec == 0 {
+		market.MoneyPrec = defaultPrec
+	}
+	if market.FeePrec == 0 {
+		market.FeePrec = defaultPrec
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.books[market.Name]; ok {
+		return fmt.Errorf("market %s already exists", market.Name)
+	}
+	b := &book{market: market}
+	e.markets = append(e.markets, b)
+	e.books[market.Name] = b
+	e.balance(market.Stock)
+	e.balance(market.Money)
+	return nil
+}
+
+// SetBalance sets the available balance of currency of the account
+func (e *Exchange) SetBalance(currency string, available p2pb2b.Decimal) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.balance(currency).Available = available
+}
+
+// Balance returns the balance of currency of the account
+func (e *Exchange) Balance(currency string) p2pb2b.AccountBalance {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return *e.balance(currency)
+}
+
+// SetNow replaces the clock of the exchange, which timestamps orders and deals
+func (e *Exchange) SetNow(now func() time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.now = now
+}
+
+// AddOrder places a limit order of another account, e.g. to provide liquidity the
+// account can trade against. It is matched like any order but does not change the
+// balances of the account and is not returned by its queries. It returns the order ID.
+func (e *Exchange) AddOrder(market string, side string, amount p2pb2b.Decimal, price p2pb2b.Decimal) (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	o, err := e.placeOrder(market, side, amount, price, false)
+	if err != nil {
+		return 0, err
+	}
+	return o.id, nil
+}
+
+func (e *Exchange) balance(currency string) *p2pb2b.AccountBalance {
+	b, ok := e.balances[currency]
+	if !ok {
+		b = &p2pb2b.AccountBalance{}
+		e.balances[currency] = b
+	}
+	return b
+}
+
+func (e *Exchange) book(endpoint string, market string) (*book, error) {
+	if market == "" {
+		return nil, fmt.Errorf("parameter market must not be empty")
+	}
+	b, ok := e.books[market]
+	if !ok {
+		return nil, apiError(endpoint, "Market not found.")
+	}
+	return b, nil
+}
+
+func (e *Exchange) timestamp() float64 {
+	return float64(e.now().UnixNano()) / 1e9
+}
+
+// response returns a successful response with the current time of the exchange
+func (e *Exchange) response() (p2pb2b.Response, float64) {
+	return p2pb2b.Response{Success: true}, e.timestamp()
+}
+
+// apiError returns the error the client reports for a response with success=false
+func apiError(endpoint string, message string) error {
+	body, _ := json.Marshal(map[string]interface{}{
+		"success": false,
+		"message": message,
+		"result":  []interface{}{},
+	})
+	return &p2pb2b.APIError{
+		StatusCode: http.StatusOK,
+		Message:    message,
+		Endpoint:   endpoint,
+		Body:       body,
+	}
+}
+
+// page returns the bounds of the slice [offset, offset+limit) of n elements
+func page(n int, offset int64, limit int64) (int, int) {
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	start := int(math.Min(float64(offset), float64(n)))
+	end := int(math.Min(float64(offset+limit), float64(n)))
+	return start, end
+}
@@ -0,0 +1,212 @@
+package p2pb2btest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	p2pb2b "github.com/krinklesaurus/go-p2pb2b"
+	"github.com/stretchr/testify/assert"
+)
+
+var d = p2pb2b.MustParseDecimal
+
+func newTestExchange() *Exchange {
+	e := NewExchange(Market{Name: "ETH_BTC", Stock: "ETH", Money: "BTC", MinAmount: d("0.001"), MakerFee: d("0.001"), TakerFee: d("0.002")})
+	now := time.Unix(1574197772, 0)
+	e.SetNow(func() time.Time { return now })
+	return e
+}
+
+func createOrder(t *testing.T, e *Exchange, side string, amount string, price string) p2pb2b.Order {
+	resp, err := e.CreateOrder(&p2pb2b.CreateOrderRequest{Market: "ETH_BTC", Side: side, Amount: d(amount), Price: d(price)})
+	assert.Nil(t, err)
+	if resp == nil {
+		t.FailNow()
+	}
+	assert.True(t, resp.Success)
+	return resp.Result
+}
+
+// assertDecimal asserts that actual is the number expected, regardless of decimal places
+func assertDecimal(t *testing.T, expected string, actual p2pb2b.Decimal) {
+	assert.True(t, d(expected).Equal(actual), "expected %s, got %s", expected, actual)
+}
+
+func assertBalance(t *testing.T, e *Exchange, currency string, available string, freeze string) {
+	balance := e.Balance(currency)
+	assertDecimal(t, available, balance.Available)
+	assertDecimal(t, freeze, balance.Freeze)
+}
+
+func TestPriceTimePriority(t *testing.T) {
+	e := newTestExchange()
+	first, _ := e.AddOrder("ETH_BTC", "sell", d("1"), d("0.021"))
+	second, _ := e.AddOrder("ETH_BTC", "sell", d("1"), d("0.021"))
+	cheapest, _ := e.AddOrder("ETH_BTC", "sell", d("1"), d("0.02"))
+	_, _ = e.AddOrder("ETH_BTC", "sell", d("1"), d("0.03"))
+	e.SetBalance("BTC", d("1"))
+
+	// the cheapest ask is taken first, then the older of two asks at the same price
+	order := createOrder(t, e, "buy", "2.5", "0.025")
+	assertDecimal(t, "0", order.Left)
+	assertDecimal(t, "2.5", order.DealStock)
+	assertDecimal(t, "0.0515", order.DealMoney)
+	assertDecimal(t, "0.005", order.DealFee)
+
+	deals, err := e.QueryDeals(&p2pb2b.QueryDealsRequest{OrderID: order.OrderID})
+	assert.Nil(t, err)
+	records := deals.Result.Records
+	if assert.Len(t, records, 3) {
+		assert.Equal(t, second, records[0].DealOrderID)
+		assertDecimal(t, "0.5", records[0].Amount)
+		assert.Equal(t, first, records[1].DealOrderID)
+		assert.Equal(t, cheapest, records[2].DealOrderID)
+		assertDecimal(t, "0.02", records[2].Price)
+		assert.Equal(t, int64(roleTaker), records[2].Role)
+	}
+
+	// the paid price was below the limit, the rest of the frozen money is released
+	assertBalance(t, e, "BTC", "0.9485", "0")
+	assertBalance(t, e, "ETH", "2.495", "0")
+
+	book, err := e.GetOrderBook("ETH_BTC", "sell", 0, 10)
+	assert.Nil(t, err)
+	if assert.Len(t, book.Result.Orders, 2) {
+		assert.Equal(t, int(second), book.Result.Orders[0].ID)
+		assertDecimal(t, "0.5", book.Result.Orders[0].Left)
+		assertDecimal(t, "0.03", book.Result.Orders[1].Price)
+	}
+}
+
+func TestRestingOrderConsistency(t *testing.T) {
+	e := newTestExchange()
+	e.SetBalance("BTC", d("1"))
+	e.SetBalance("ETH", d("1"))
+
+	bid := createOrder(t, e, "buy", "2", "0.02")
+	ask := createOrder(t, e, "sell", "1", "0.03")
+	assertDecimal(t, "2", bid.Left)
+	assertBalance(t, e, "BTC", "0.96", "0.04")
+	assertBalance(t, e, "ETH", "0", "1")
+
+	unexecuted, err := e.QueryUnexecuted(&p2pb2b.QueryUnexecutedRequest{Market: "ETH_BTC", Limit: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), unexecuted.Result.Total)
+	assert.Equal(t, ask.OrderID, unexecuted.Result.Result[0].ID)
+
+	depth, err := e.GetDepthResult("ETH_BTC", 10)
+	assert.Nil(t, err)
+	if assert.Len(t, depth.Result.Asks, 1) && assert.Len(t, depth.Result.Bids, 1) {
+		assertDecimal(t, "0.03", depth.Result.Asks[0][0])
+		assertDecimal(t, "1", depth.Result.Asks[0][1])
+		assertDecimal(t, "0.02", depth.Result.Bids[0][0])
+		assertDecimal(t, "2", depth.Result.Bids[0][1])
+	}
+
+	// another account sells into the bid
+	_, err = e.AddOrder("ETH_BTC", "sell", d("0.5"), d("0.019"))
+	assert.Nil(t, err)
+	assertBalance(t, e, "ETH", "0.4995", "1")
+	assertBalance(t, e, "BTC", "0.96", "0.03")
+
+	history, err := e.GetHistory("ETH_BTC", 0, 10)
+	assert.Nil(t, err)
+	if assert.Len(t, history.Result, 1) {
+		assert.Equal(t, "sell", history.Result[0].Type)
+		assertDecimal(t, "0.02", history.Result[0].Price)
+	}
+	ticker, err := e.GetTicker("ETH_BTC")
+	assert.Nil(t, err)
+	assertDecimal(t, "0.02", ticker.Result.Last)
+	assertDecimal(t, "0.02", ticker.Result.Bid)
+	assertDecimal(t, "0.03", ticker.Result.Ask)
+	assertDecimal(t, "0.5", ticker.Result.Volume)
+
+	// the partially filled bid is closed and its frozen money released
+	canceled, err := e.CancelOrder(&p2pb2b.CancelOrderRequest{Market: "ETH_BTC", OrderID: bid.OrderID})
+	assert.Nil(t, err)
+	assertDecimal(t, "1.5", canceled.Result.Left)
+	assertBalance(t, e, "BTC", "0.99", "0")
+
+	executed, err := e.QueryExecuted(&p2pb2b.QueryExecutedRequest{Limit: 10})
+	assert.Nil(t, err)
+	if assert.Len(t, executed.Result["ETH_BTC"], 1) {
+		assert.Equal(t, bid.OrderID, executed.Result["ETH_BTC"][0].ID)
+		assertDecimal(t, "0.5", executed.Result["ETH_BTC"][0].DealStock)
+	}
+	unexecuted, err = e.QueryUnexecuted(&p2pb2b.QueryUnexecutedRequest{Market: "ETH_BTC", Limit: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), unexecuted.Result.Total)
+}
+
+func TestExchangeErrors(t *testing.T) {
+	e := newTestExchange()
+	e.SetBalance("BTC", d("0.01"))
+
+	_, err := e.CreateOrder(&p2pb2b.CreateOrderRequest{Market: "ETH_BTC", Side: "buy", Amount: d("1"), Price: d("0.02")})
+	assert.True(t, p2pb2b.IsInsufficientFunds(err))
+	assertBalance(t, e, "BTC", "0.01", "0")
+
+	_, err = e.CreateOrder(&p2pb2b.CreateOrderRequest{Market: "ETH_BTC", Side: "buy", Amount: d("0.0001"), Price: d("0.02")})
+	assert.NotNil(t, err)
+	_, err = e.CreateOrder(&p2pb2b.CreateOrderRequest{Market: "LTC_BTC", Side: "buy", Amount: d("1"), Price: d("0.02")})
+	assert.NotNil(t, err)
+
+	_, err = e.CancelOrder(&p2pb2b.CancelOrderRequest{Market: "ETH_BTC", OrderID: 42})
+	assert.True(t, p2pb2b.IsOrderNotFound(err))
+	_, err = e.QueryDeals(&p2pb2b.QueryDealsRequest{OrderID: 42})
+	assert.True(t, p2pb2b.IsOrderNotFound(err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = e.GetMarketsCtx(ctx)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestMarketData(t *testing.T) {
+	e := newTestExchange()
+	markets, err := e.GetMarkets()
+	assert.Nil(t, err)
+	assert.Equal(t, []p2pb2b.Market{{Name: "ETH_BTC", Stock: "ETH", Money: "BTC", MoneyPrec: 8, StockPrec: 8, FeePrec: 8, MinAmount: d("0.001")}}, markets.Result)
+	assert.Equal(t, float64(1574197772), markets.CurrentTime)
+
+	symbols, err := e.GetSymbols()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ETH_BTC"}, symbols.Result)
+
+	products, err := e.GetProducts()
+	assert.Nil(t, err)
+	assert.Equal(t, []p2pb2b.Product{{ID: "ETH_BTC", FromSymbol: "ETH", ToSymbol: "BTC"}}, products.Result)
+
+	tickers, err := e.GetTickers()
+	assert.Nil(t, err)
+	assert.Equal(t, 1574197772, tickers.Result["ETH_BTC"].At)
+
+	assert.NotNil(t, e.AddMarket(Market{Name: "ETH_BTC", Stock: "ETH", Money: "BTC"}))
+	assert.NotNil(t, e.AddMarket(Market{Name: "LTC_BTC"}))
+}
+
+func TestRoundedDealsConserveBalances(t *testing.T) {
+	e := NewExchange(Market{Name: "ETH_BTC", Stock: "ETH", Money: "BTC", StockPrec: 1, MoneyPrec: 2})
+	e.SetBalance("BTC", d("1"))
+
+	// 0.15 BTC are frozen for the bid, but every deal of 0.1 ETH costs 0.015, rounded to 0.02
+	bid := createOrder(t, e, "buy", "1", "0.15")
+	assertBalance(t, e, "BTC", "0.85", "0.15")
+	for i := 1; i <= 8; i++ {
+		_, err := e.AddOrder("ETH_BTC", "sell", d("0.1"), d("0.15"))
+		assert.Nil(t, err)
+		balance := e.Balance("BTC")
+		assert.True(t, balance.Freeze.Sign() >= 0, "freeze %s after %d deals", balance.Freeze, i)
+		spent := d("0.02").Mul(p2pb2b.NewDecimalFromInt(int64(i)))
+		assertDecimal(t, d("1").Sub(spent).String(), balance.Available.Add(balance.Freeze))
+	}
+	assertBalance(t, e, "BTC", "0.84", "0")
+
+	canceled, err := e.CancelOrder(&p2pb2b.CancelOrderRequest{Market: "ETH_BTC", OrderID: bid.OrderID})
+	assert.Nil(t, err)
+	assertDecimal(t, "0.2", canceled.Result.Left)
+	assertBalance(t, e, "BTC", "0.84", "0")
+	assertBalance(t, e, "ETH", "0.8", "0")
+}
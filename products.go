@@ -1,10 +1,7 @@
 package p2pb2b
 
 import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
+	"context"
 )
 
 type ProductsResp struct {
@@ -21,24 +18,12 @@ type Product struct {
 }
 
 func (c *client) GetProducts() (*ProductsResp, error) {
-	url := fmt.Sprintf("%s/public/products", c.url)
-	resp, err := c.sendGet(url, nil)
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return c.GetProductsCtx(context.Background())
+}
 
+func (c *client) GetProductsCtx(ctx context.Context) (*ProductsResp, error) {
 	var result ProductsResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, productsEndpoint, "", nil, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
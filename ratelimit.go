@@ -0,0 +1,203 @@
+package p2pb2b
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimitExceeded is returned by a client in fail-fast mode when its own rate limit is exhausted.
+// Unlike ErrRateLimited the request has not been sent to the exchange.
+var ErrRateLimitExceeded = errors.New("client side rate limit exceeded")
+
+// endpointClass distinguishes public market data endpoints from signed trading and account endpoints
+type endpointClass int
+
+const (
+	classPublic endpointClass = iota
+	classPrivate
+)
+
+func (e endpointClass) String() string {
+	if e == classPrivate {
+		return "private"
+	}
+	return "public"
+}
+
+// RateLimit is the budget of a token bucket
+type RateLimit struct {
+	// Rate is the number of requests per second, values <= 0 disable the limit
+	Rate float64
+	// Burst is the maximum number of requests sent at once, at least 1
+	Burst int
+}
+
+// RateLimiterConfig configures the client side rate limiter
+type RateLimiterConfig struct {
+	// Public limits the public market data endpoints
+	Public RateLimit
+	// Private limits the signed trading and account endpoints
+	Private RateLimit
+	// FailFast makes requests fail with ErrRateLimitExceeded instead of waiting for a token
+	FailFast bool
+	// SlowDown is how long the rate of a class is halved after the exchange answered with 429.
+	// Requests of the class are paused for the Retry-After duration, or one second if not present.
+	// Defaults to 30 seconds.
+	SlowDown time.Duration
+}
+
+type rateLimiter struct {
+	buckets  [2]*tokenBucket
+	failFast bool
+}
+
+func newRateLimiter(config RateLimiterConfig) (*rateLimiter, error) {
+	slowDown := config.SlowDown
+	if slowDown == 0 {
+		slowDown = 30 * time.Second
+	}
+	if slowDown < 0 {
+		return nil, fmt.Errorf("rate limiter slow down must not be < 0")
+	}
+	limiter := &rateLimiter{failFast: config.FailFast}
+	for class, limit := range map[endpointClass]RateLimit{classPublic: config.Public, classPrivate: config.Private} {
+		if limit.Rate <= 0 {
+			continue
+		}
+		burst := limit.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		limiter.buckets[class] = &tokenBucket{
+			rate:     limit.Rate,
+			burst:    float64(burst),
+			tokens:   float64(burst),
+			slowDown: slowDown,
+			now:      time.Now,
+		}
+	}
+	return limiter, nil
+}
+
+// wait blocks until a request of the class may be sent, or fails fast if configured
+func (l *rateLimiter) wait(ctx context.Context, class endpointClass) error {
+	if l == nil || l.buckets[class] == nil {
+		return nil
+	}
+	bucket := l.buckets[class]
+	delay, ok := bucket.reserve(l.failFast)
+	if !ok {
+		return ErrRateLimitExceeded
+	}
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		bucket.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// throttled slows the class down after the exchange answered with 429
+func (l *rateLimiter) throttled(class endpointClass, retryAfter time.Duration) {
+	if l == nil || l.buckets[class] == nil {
+		return
+	}
+	if retryAfter <= 0 {
+		retryAfter = time.Second
+	}
+	l.buckets[class].throttle(retryAfter)
+}
+
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	last     time.Time
+	slowDown time.Duration
+
+	pausedUntil time.Time
+	slowedUntil time.Time
+
+	now func() time.Time
+}
+
+func (b *tokenBucket) currentRate(now time.Time) float64 {
+	if now.Before(b.slowedUntil) {
+		return b.rate / 2
+	}
+	return b.rate
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if !b.last.IsZero() && now.After(b.last) {
+		from := b.last
+		if from.Before(b.pausedUntil) {
+			from = b.pausedUntil
+		}
+		if now.After(from) {
+			b.tokens += now.Sub(from).Seconds() * b.currentRate(now)
+		}
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+}
+
+// reserve takes a token and returns how long to wait until it can be used. In fail-fast
+// mode no token is taken and false is returned if none is available right now.
+func (b *tokenBucket) reserve(failFast bool) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.refill(now)
+
+	var delay time.Duration
+	if now.Before(b.pausedUntil) {
+		delay = b.pausedUntil.Sub(now)
+	}
+	if b.tokens < 1 {
+		delay += time.Duration((1 - b.tokens) / b.currentRate(now) * float64(time.Second))
+	}
+	if failFast && delay > 0 {
+		return 0, false
+	}
+	b.tokens--
+	return delay, true
+}
+
+// cancel returns a token reserved by a request which was not sent
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+func (b *tokenBucket) throttle(pause time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.refill(now)
+	if b.tokens > 0 {
+		b.tokens = 0
+	}
+	if until := now.Add(pause); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+	b.slowedUntil = b.pausedUntil.Add(b.slowDown)
+}
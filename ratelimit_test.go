@@ -0,0 +1,150 @@
+package p2pb2b
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestBucket(rate float64, burst int, clock *fakeClock) *tokenBucket {
+	return &tokenBucket{
+		rate:     rate,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		slowDown: 10 * time.Second,
+		now:      clock.Now,
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1574197772, 0)}
+	bucket := newTestBucket(2, 2, clock)
+
+	delay, ok := bucket.reserve(false)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), delay)
+	delay, ok = bucket.reserve(false)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), delay)
+
+	// burst is used up, the next token is available in 500ms
+	_, ok = bucket.reserve(true)
+	assert.False(t, ok)
+	delay, ok = bucket.reserve(false)
+	assert.True(t, ok)
+	assert.Equal(t, 500*time.Millisecond, delay)
+
+	// the reserved token was returned, a new one is available after another 500ms
+	bucket.cancel()
+	clock.now = clock.now.Add(time.Second)
+	delay, ok = bucket.reserve(true)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), delay)
+}
+
+func TestTokenBucketThrottle(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1574197772, 0)}
+	bucket := newTestBucket(2, 2, clock)
+
+	bucket.throttle(3 * time.Second)
+	delay, ok := bucket.reserve(false)
+	assert.True(t, ok)
+	// 3s pause plus one token at the halved rate of 1 per second
+	assert.Equal(t, 4*time.Second, delay)
+
+	// after the slow down window the full rate is restored
+	clock.now = clock.now.Add(20 * time.Second)
+	assert.Equal(t, float64(2), bucket.currentRate(clock.now))
+}
+
+func TestRateLimiterFailFast(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"success":true,"message":"","result":[]}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"message":"","result":{}}`))
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithRateLimiter(RateLimiterConfig{
+			Public:   RateLimit{Rate: 0.001, Burst: 1},
+			Private:  RateLimit{Rate: 0.001, Burst: 1},
+			FailFast: true,
+		}))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	_, err = client.GetMarkets()
+	assert.Nil(t, err)
+	_, err = client.GetMarkets()
+	assert.Equal(t, ErrRateLimitExceeded, err)
+
+	// private endpoints have their own budget
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	assert.Nil(t, err)
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	assert.Equal(t, ErrRateLimitExceeded, err)
+}
+
+func TestRateLimiterWaitHonorsContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":[]}`))
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithRateLimiter(RateLimiterConfig{
+			Public: RateLimit{Rate: 0.001, Burst: 1},
+		}))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	_, err = client.GetSymbols()
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.GetSymbolsCtx(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestRateLimiterSlowsDownOn429(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithRateLimiter(RateLimiterConfig{
+			Public:   RateLimit{Rate: 100, Burst: 10},
+			FailFast: true,
+		}))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	_, err = client.GetSymbols()
+	assert.True(t, IsRateLimited(err))
+	_, err = client.GetSymbols()
+	assert.Equal(t, ErrRateLimitExceeded, err)
+}
@@ -0,0 +1,148 @@
+package p2pb2b
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how failed requests are retried. It is only applied to
+// public endpoints and idempotent private queries (QueryUnexecuted, QueryExecuted,
+// QueryDeals, PostBalances and PostCurrencyBalance), never to CreateOrder and CancelOrder.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one, values <= 1 disable retries
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, including delays requested with Retry-After
+	MaxBackoff time.Duration
+	// Multiplier is the factor the delay grows with after every attempt
+	Multiplier float64
+	// Jitter randomizes each delay by +/- the given fraction, e.g. 0.2 for +/- 20%
+	Jitter float64
+	// RetryableStatus are the HTTP status codes which are retried, transport errors are always retried
+	RetryableStatus []int
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with 3 attempts and exponential backoff
+// starting at 200ms, retrying 429 and 5xx gateway errors.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatus: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// OrderReconciler is called when CreateOrder fails in a way that leaves it unknown whether
+// the order was created, i.e. on transport errors and 5xx responses. It can look up the
+// order, e.g. with QueryUnexecuted, and return its result instead of the error.
+type OrderReconciler func(ctx context.Context, request *CreateOrderRequest, cause error) (*CreateOrderResp, error)
+
+func (p RetryPolicy) isRetryableStatus(statusCode int) bool {
+	for _, s := range p.RetryableStatus {
+		if s == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) shouldRetry(ctx context.Context, resp *response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		var urlErr *url.Error
+		return errors.As(err, &urlErr)
+	}
+	return p.isRetryableStatus(resp.StatusCode)
+}
+
+// backoff returns the delay after the given attempt, honoring a Retry-After header of resp
+// up to MaxBackoff
+func (p RetryPolicy) backoff(attempt int, resp *response) time.Duration {
+	if resp != nil {
+		if delay, ok := retryAfter(resp.Header); ok {
+			if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+				delay = p.MaxBackoff
+			}
+			return delay
+		}
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// retryAfter returns the delay of a Retry-After header given in seconds
+func retryAfter(header http.Header) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// withRetry calls send until it succeeds, fails permanently or the retry policy is
+// exhausted. send must build a new request on every call, so signed requests get a fresh nonce.
+func (c *client) withRetry(ctx context.Context, idempotent bool, send func() (*response, error)) (*response, error) {
+	attempts := 1
+	if idempotent && c.retry.MaxAttempts > 1 {
+		attempts = c.retry.MaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := send()
+		if attempt >= attempts || !c.retry.shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+		delay := c.retry.backoff(attempt, resp)
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isOutcomeUnknown reports whether a request failed without a definite answer of the exchange
+func isOutcomeUnknown(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,177 @@
+package p2pb2b
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRetryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	return policy
+}
+
+func TestRetryPublicEndpoint(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":["ETH"]}`))
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithRetryPolicy(testRetryPolicy()))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	resp, err := client.GetSymbols()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ETH"}, resp.Result)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetryExhausted(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithRetryPolicy(testRetryPolicy()))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	_, err = client.GetSymbols()
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetryPrivateQueryUsesFreshNonce(t *testing.T) {
+	var nonces []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqBody, _ := ioutil.ReadAll(r.Body)
+		var request Request
+		assert.Nil(t, json.Unmarshal(reqBody, &request))
+		nonces = append(nonces, request.Nonce)
+		if len(nonces) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":{}}`))
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithRetryPolicy(testRetryPolicy()), WithNonceSource(&fixedNonceSource{}))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1", "2"}, nonces)
+}
+
+func TestCreateOrderIsNotRetriedButReconciled(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	var cause error
+	reconciled := &CreateOrderResp{Success: true, Result: Order{OrderID: 25749}}
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithRetryPolicy(testRetryPolicy()),
+		WithOrderReconciler(func(ctx context.Context, request *CreateOrderRequest, err error) (*CreateOrderResp, error) {
+			cause = err
+			assert.Equal(t, "ETH_BTC", request.Market)
+			return reconciled, nil
+		}))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	resp, err := client.CreateOrder(&CreateOrderRequest{Market: "ETH_BTC", Side: "buy", Amount: NewDecimalFromInt(1), Price: NewDecimalFromInt(1)})
+	assert.Nil(t, err)
+	assert.Equal(t, reconciled, resp)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	var apiErr *APIError
+	assert.True(t, errors.As(cause, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+}
+
+func TestRetryStopsOnContextCancel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	policy := testRetryPolicy()
+	policy.MaxAttempts = 100
+	policy.InitialBackoff = time.Second
+	policy.MaxBackoff = time.Second
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd",
+		WithRetryPolicy(policy))
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = client.GetSymbolsCtx(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(1, nil))
+	assert.Equal(t, 200*time.Millisecond, policy.backoff(2, nil))
+	assert.Equal(t, 400*time.Millisecond, policy.backoff(3, nil))
+	assert.Equal(t, time.Second, policy.backoff(5, nil))
+
+	policy.MaxBackoff = 5 * time.Second
+	resp := &response{Header: http.Header{"Retry-After": []string{"3"}}}
+	assert.Equal(t, 3*time.Second, policy.backoff(1, resp))
+
+	// a Retry-After longer than MaxBackoff is capped
+	resp = &response{Header: http.Header{"Retry-After": []string{"3600"}}}
+	assert.Equal(t, 5*time.Second, policy.backoff(1, resp))
+	policy.MaxBackoff = time.Second
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.backoff(1, nil)
+		assert.True(t, delay >= 50*time.Millisecond && delay <= 150*time.Millisecond)
+	}
+}
@@ -0,0 +1,79 @@
+package p2pb2b
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+)
+
+// Signer signs the JSON body of private requests and returns the headers to add to the
+// request, usually HeaderXTxcSignature. Implementations can delegate to an HSM or a
+// remote signing service.
+type Signer interface {
+	Sign(payload []byte) (map[string]string, error)
+}
+
+// SignerFunc is an adapter to use ordinary functions as Signer
+type SignerFunc func(payload []byte) (map[string]string, error)
+
+// Sign calls f(payload)
+func (f SignerFunc) Sign(payload []byte) (map[string]string, error) {
+	return f(payload)
+}
+
+// SignatureAlgorithm is the HMAC hash used by the built-in signer
+type SignatureAlgorithm int
+
+const (
+	// HMACSHA256 signs with HMAC-SHA256, the default
+	HMACSHA256 SignatureAlgorithm = iota
+	// HMACSHA512 signs with HMAC-SHA512
+	HMACSHA512
+)
+
+func (a SignatureAlgorithm) String() string {
+	switch a {
+	case HMACSHA256:
+		return "HMAC-SHA256"
+	case HMACSHA512:
+		return "HMAC-SHA512"
+	}
+	return fmt.Sprintf("SignatureAlgorithm(%d)", int(a))
+}
+
+func (a SignatureAlgorithm) hash() (func() hash.Hash, error) {
+	switch a {
+	case HMACSHA256:
+		return sha256.New, nil
+	case HMACSHA512:
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("unknown signature algorithm %v", a)
+}
+
+// HMACSigner is a Signer setting HeaderXTxcSignature to the hex encoded HMAC of the payload
+type HMACSigner struct {
+	secret []byte
+	hash   func() hash.Hash
+}
+
+// NewHMACSigner creates an HMACSigner with the given algorithm and API secret
+func NewHMACSigner(algorithm SignatureAlgorithm, secret string) (*HMACSigner, error) {
+	h, err := algorithm.hash()
+	if err != nil {
+		return nil, err
+	}
+	return &HMACSigner{secret: []byte(secret), hash: h}, nil
+}
+
+// Sign implements Signer
+func (s *HMACSigner) Sign(payload []byte) (map[string]string, error) {
+	h := hmac.New(s.hash, s.secret)
+	h.Write(payload)
+	return map[string]string{
+		HeaderXTxcSignature: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
@@ -0,0 +1,89 @@
+package p2pb2b
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHMACSigner(t *testing.T) {
+	payload := []byte(`{"request":"{{request}}","nonce":"{{nonce}}","market":"ETH_BTC","side":"buy","amount":"0.001","price":"1000"}`)
+
+	signer, err := NewHMACSigner(HMACSHA256, "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd")
+	assert.Nil(t, err)
+	headers, err := signer.Sign(payload)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		HeaderXTxcSignature: "b7cea8337772ecd0aa0af981037ea919b40645e8015becfdce36a3abdff6b440",
+	}, headers)
+
+	signer, err = NewHMACSigner(HMACSHA512, "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd")
+	assert.Nil(t, err)
+	headers, err = signer.Sign(payload)
+	assert.Nil(t, err)
+	assert.Equal(t, 128, len(headers[HeaderXTxcSignature]))
+
+	_, err = NewHMACSigner(SignatureAlgorithm(42), "secret")
+	assert.NotNil(t, err)
+}
+
+func TestClientWithSignatureAlgorithm(t *testing.T) {
+	secret := "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd"
+	signer, _ := NewHMACSigner(HMACSHA512, secret)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		expected, _ := signer.Sign(body)
+		assert.Equal(t, expected[HeaderXTxcSignature], r.Header.Get(HeaderXTxcSignature))
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":{}}`))
+	}))
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), secret, WithSignatureAlgorithm(HMACSHA512))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	assert.Nil(t, err)
+
+	_, err = NewClient("key", "secret", WithSignatureAlgorithm(SignatureAlgorithm(42)))
+	assert.NotNil(t, err)
+}
+
+func TestClientWithCustomSigner(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "remote-signature", r.Header.Get(HeaderXTxcSignature))
+		assert.Equal(t, "hsm-1", r.Header.Get("X-Signer"))
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true,"message":"","result":{}}`))
+	}))
+	defer ts.Close()
+
+	remote := SignerFunc(func(payload []byte) (map[string]string, error) {
+		return map[string]string{HeaderXTxcSignature: "remote-signature", "X-Signer": "hsm-1"}, nil
+	})
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "", WithSigner(remote))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	assert.Nil(t, err)
+
+	failing := SignerFunc(func(payload []byte) (map[string]string, error) {
+		return nil, errors.New("hsm unavailable")
+	})
+	client, err = newClientWithURL(ts.URL, uuid.NewV4().String(), "", WithSigner(failing))
+	if err != nil {
+		t.Error(err.Error())
+	}
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	assert.NotNil(t, err)
+}
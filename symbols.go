@@ -1,10 +1,7 @@
 package p2pb2b
 
 import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
+	"context"
 )
 
 type SymbolsResp struct {
@@ -15,24 +12,12 @@ type SymbolsResp struct {
 }
 
 func (c *client) GetSymbols() (*SymbolsResp, error) {
-	url := fmt.Sprintf("%s/public/symbols", c.url)
-	resp, err := c.sendGet(url, nil)
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return c.GetSymbolsCtx(context.Background())
+}
 
+func (c *client) GetSymbolsCtx(ctx context.Context) (*SymbolsResp, error) {
 	var result SymbolsResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, symbolsEndpoint, "", nil, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -1,10 +1,9 @@
 package p2pb2b
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"net/url"
 )
 
 type TickerResp struct {
@@ -15,36 +14,25 @@ type TickerResp struct {
 }
 
 type Ticker struct {
-	Bid    float64 `json:"bid,string"`
-	Ask    float64 `json:"ask,string"`
-	Open   float64 `json:"open,string"`
-	High   float64 `json:"high,string"`
-	Low    float64 `json:"low,string"`
-	Last   float64 `json:"last,string"`
-	Volume float64 `json:"volume,string"`
-	Deal   float64 `json:"deal,string"`
-	Change float64 `json:"change,string"`
+	Bid    Decimal `json:"bid"`
+	Ask    Decimal `json:"ask"`
+	Open   Decimal `json:"open"`
+	High   Decimal `json:"high"`
+	Low    Decimal `json:"low"`
+	Last   Decimal `json:"last"`
+	Volume Decimal `json:"volume"`
+	Deal   Decimal `json:"deal"`
+	Change Decimal `json:"change"`
 }
 
 func (c *client) GetTicker(market string) (*TickerResp, error) {
-	url := fmt.Sprintf("%s/public/ticker?market=%s", c.url, market)
-	resp, err := c.sendGet(url, nil)
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return c.GetTickerCtx(context.Background(), market)
+}
 
+func (c *client) GetTickerCtx(ctx context.Context, market string) (*TickerResp, error) {
+	query := fmt.Sprintf("market=%s", url.QueryEscape(market))
 	var result TickerResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, tickerEndpoint, query, nil, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
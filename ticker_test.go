@@ -1,10 +1,13 @@
 package p2pb2b
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
@@ -60,14 +63,35 @@ func TestGetTicker(t *testing.T) {
 	assert.Equal(t, 1574197469.668056, resp.CacheTime)
 	assert.Equal(t, 1574197469.668141, resp.CurrentTime)
 
-	assert.Equal(t, 0.021475, resp.Result.Bid)
-	assert.Equal(t, 0.0215, resp.Result.Ask)
-	assert.Equal(t, 0.021763, resp.Result.Open)
-	assert.Equal(t, 0.021855, resp.Result.High)
-	assert.Equal(t, 0.021422, resp.Result.Low)
-	assert.Equal(t, 0.021489, resp.Result.Last)
-	assert.Equal(t, 160302.558, resp.Result.Volume)
-	assert.Equal(t, 3469.740614926, resp.Result.Deal)
-	assert.Equal(t, -0.95, resp.Result.Change)
+	assert.Equal(t, "0.021475", resp.Result.Bid.String())
+	assert.Equal(t, "0.0215", resp.Result.Ask.String())
+	assert.Equal(t, "0.021763", resp.Result.Open.String())
+	assert.Equal(t, "0.021855", resp.Result.High.String())
+	assert.Equal(t, "0.021422", resp.Result.Low.String())
+	assert.Equal(t, "0.021489", resp.Result.Last.String())
+	assert.Equal(t, "160302.558", resp.Result.Volume.String())
+	assert.Equal(t, "3469.740614926", resp.Result.Deal.String())
+	assert.Equal(t, "-0.95", resp.Result.Change.String())
 
 }
+
+func TestGetTickerCtxDeadline(t *testing.T) {
+	unblock := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer ts.Close()
+	defer close(unblock)
+
+	client, err := newClientWithURL(ts.URL, uuid.NewV4().String(), "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd")
+	if err != nil {
+		t.Error(err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	resp, err := client.GetTickerCtx(ctx, "ETH_BTC")
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), fmt.Sprintf("unexpected error: %v", err))
+}
@@ -1,10 +1,7 @@
 package p2pb2b
 
 import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
+	"context"
 )
 
 type TickersResp struct {
@@ -20,34 +17,22 @@ type TickersResult struct {
 }
 
 type TickersEntry struct {
-	Bid    float64 `json:"bid,string"`
-	Ask    float64 `json:"ask,string"`
-	Low    float64 `json:"low,string"`
-	High   float64 `json:"high,string"`
-	Last   float64 `json:"last,string"`
-	Volume float64 `json:"vol,string"`
-	Change float64 `json:"change,string"`
+	Bid    Decimal `json:"bid"`
+	Ask    Decimal `json:"ask"`
+	Low    Decimal `json:"low"`
+	High   Decimal `json:"high"`
+	Last   Decimal `json:"last"`
+	Volume Decimal `json:"vol"`
+	Change Decimal `json:"change"`
 }
 
 func (c *client) GetTickers() (*TickersResp, error) {
-	url := fmt.Sprintf("%s/public/tickers", c.url)
-	resp, err := c.sendGet(url, nil)
-	if err != nil {
-		return nil, err
-	}
-	err = checkHTTPStatus(*resp, http.StatusOK)
-	if err != nil {
-		return nil, err
-	}
-
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	return c.GetTickersCtx(context.Background())
+}
 
+func (c *client) GetTickersCtx(ctx context.Context) (*TickersResp, error) {
 	var result TickersResp
-	err = json.Unmarshal(bodyBytes, &result)
-	if err != nil {
+	if err := c.call(ctx, tickersEndpoint, "", nil, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
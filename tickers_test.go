@@ -11,7 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func ExampleGetTickers(t *testing.T) {
+func TestGetTickers(t *testing.T) {
 	pseudoAPIKey := uuid.NewV4()
 	pseudoAPISecret := "4a894c5c-8a7e-4337-bb6b-9fde16e3dddd"
 	body := `{
@@ -80,21 +80,21 @@ func ExampleGetTickers(t *testing.T) {
 
 	assert.NotEmpty(t, resp.Result["ETH_BTC"])
 	assert.Equal(t, 1574197772, resp.Result["ETH_BTC"].At)
-	assert.Equal(t, 0.021477, resp.Result["ETH_BTC"].Ticker.Bid)
-	assert.Equal(t, 0.021491, resp.Result["ETH_BTC"].Ticker.Ask)
-	assert.Equal(t, 0.021422, resp.Result["ETH_BTC"].Ticker.Low)
-	assert.Equal(t, 0.021855, resp.Result["ETH_BTC"].Ticker.High)
-	assert.Equal(t, 0.021477, resp.Result["ETH_BTC"].Ticker.Last)
-	assert.Equal(t, 159706.449, resp.Result["ETH_BTC"].Ticker.Volume)
-	assert.Equal(t, -1.05, resp.Result["ETH_BTC"].Ticker.Change)
+	assert.Equal(t, "0.021477", resp.Result["ETH_BTC"].Ticker.Bid.String())
+	assert.Equal(t, "0.021491", resp.Result["ETH_BTC"].Ticker.Ask.String())
+	assert.Equal(t, "0.021422", resp.Result["ETH_BTC"].Ticker.Low.String())
+	assert.Equal(t, "0.021855", resp.Result["ETH_BTC"].Ticker.High.String())
+	assert.Equal(t, "0.021477", resp.Result["ETH_BTC"].Ticker.Last.String())
+	assert.Equal(t, "159706.449", resp.Result["ETH_BTC"].Ticker.Volume.String())
+	assert.Equal(t, "-1.05", resp.Result["ETH_BTC"].Ticker.Change.String())
 
 	assert.NotEmpty(t, resp.Result["BTC_USD"])
 	assert.Equal(t, 1574197772, resp.Result["BTC_USD"].At)
-	assert.Equal(t, 8067.06, resp.Result["BTC_USD"].Ticker.Bid)
-	assert.Equal(t, 8149.88, resp.Result["BTC_USD"].Ticker.Ask)
-	assert.Equal(t, 8003.5, resp.Result["BTC_USD"].Ticker.Low)
-	assert.Equal(t, 8348.5, resp.Result["BTC_USD"].Ticker.High)
-	assert.Equal(t, 8107.72, resp.Result["BTC_USD"].Ticker.Last)
-	assert.Equal(t, 34976.482947, resp.Result["BTC_USD"].Ticker.Volume)
-	assert.Equal(t, -1.28, resp.Result["BTC_USD"].Ticker.Change)
+	assert.Equal(t, "8067.06", resp.Result["BTC_USD"].Ticker.Bid.String())
+	assert.Equal(t, "8149.88", resp.Result["BTC_USD"].Ticker.Ask.String())
+	assert.Equal(t, "8003.5", resp.Result["BTC_USD"].Ticker.Low.String())
+	assert.Equal(t, "8348.5", resp.Result["BTC_USD"].Ticker.High.String())
+	assert.Equal(t, "8107.72", resp.Result["BTC_USD"].Ticker.Last.String())
+	assert.Equal(t, "34976.482947", resp.Result["BTC_USD"].Ticker.Volume.String())
+	assert.Equal(t, "-1.28", resp.Result["BTC_USD"].Ticker.Change.String())
 }
@@ -0,0 +1,202 @@
+package p2pb2b
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// maxRequestBodySize is the maximum size of a signed request body accepted by VerifyRequest
+const maxRequestBodySize = 1 << 20
+
+var (
+	// ErrUnknownAPIKey is returned by VerifyRequest for a missing or unknown API key
+	ErrUnknownAPIKey = errors.New("unknown api key")
+	// ErrPayloadMismatch is returned by VerifyRequest if the payload header does not match the body
+	ErrPayloadMismatch = errors.New("payload does not match body")
+	// ErrInvalidSignature is returned by VerifyRequest if the signature does not match the body
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrInvalidNonce is returned for a missing or malformed nonce, or one not greater than the last
+	ErrInvalidNonce = errors.New("invalid nonce")
+	// ErrRequestPathMismatch is returned by VerifyRequest if the request field is not the path of the URL
+	ErrRequestPathMismatch = errors.New("request path mismatch")
+	// ErrRequestTooLarge is returned by VerifyRequest if the body exceeds 1 MiB
+	ErrRequestTooLarge = errors.New("request too large")
+)
+
+// SecretLookup returns the API secret of apiKey, or an error if the key is unknown
+type SecretLookup func(apiKey string) (string, error)
+
+// VerifiedRequest is a signed request which passed VerifyRequest
+type VerifiedRequest struct {
+	// APIKey is the API key of the X-TXC-APIKEY header
+	APIKey string
+	// Path is the request field of the body, e.g. /api/v1/order/new
+	Path string
+	// Nonce is the nonce field of the body
+	Nonce int64
+	// Body is the JSON body
+	Body []byte
+}
+
+// VerifyRequest verifies a signed request the way the exchange does, mirroring the
+// requests of the client: the X-TXC-PAYLOAD header must be the base64 encoded body,
+// X-TXC-SIGNATURE the hex encoded HMAC-SHA256 or HMAC-SHA512 of the body with the secret
+// of the X-TXC-APIKEY header, and the request field of the body the path of the URL.
+// The body of r can be read again afterwards. Nonces are only parsed; use a NonceTracker
+// or VerifyHandler to reject replayed nonces.
+func VerifyRequest(r *http.Request, secretLookup SecretLookup) (*VerifiedRequest, error) {
+	apiKey := r.Header.Get(HeaderXTxcAPIKey)
+	if apiKey == "" {
+		return nil, fmt.Errorf("header %s is missing, %w", HeaderXTxcAPIKey, ErrUnknownAPIKey)
+	}
+	secret, err := secretLookup(apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("api key %s, %v, %w", apiKey, err, ErrUnknownAPIKey)
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = readLimited(r.Body, maxRequestBodySize)
+		r.Body.Close()
+		if errors.Is(err, ErrResponseTooLarge) {
+			return nil, fmt.Errorf("request body exceeds %d bytes, %w", maxRequestBodySize, ErrRequestTooLarge)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading request body, %w", err)
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(r.Header.Get(HeaderXTxcPayloard))
+	if err != nil || !bytes.Equal(payload, body) {
+		return nil, fmt.Errorf("header %s is not the base64 encoded body, %w", HeaderXTxcPayloard, ErrPayloadMismatch)
+	}
+
+	signature, err := hex.DecodeString(r.Header.Get(HeaderXTxcSignature))
+	if err != nil || !validSignature(signature, body, secret) {
+		return nil, fmt.Errorf("header %s does not match the body, %w", HeaderXTxcSignature, ErrInvalidSignature)
+	}
+
+	var fields struct {
+		Request string `json:"request"`
+		Nonce   string `json:"nonce"`
+	}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("error parsing request body, %v", err)
+	}
+	if fields.Request != r.URL.Path {
+		return nil, fmt.Errorf("request %s sent to %s, %w", fields.Request, r.URL.Path, ErrRequestPathMismatch)
+	}
+	nonce, err := strconv.ParseInt(fields.Nonce, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("nonce %q is not a number, %w", fields.Nonce, ErrInvalidNonce)
+	}
+	return &VerifiedRequest{APIKey: apiKey, Path: fields.Request, Nonce: nonce, Body: body}, nil
+}
+
+// validSignature reports whether signature is the HMAC of body with secret, using the
+// algorithm matching its length
+func validSignature(signature []byte, body []byte, secret string) bool {
+	for _, algorithm := range []SignatureAlgorithm{HMACSHA256, HMACSHA512} {
+		h, _ := algorithm.hash()
+		mac := hmac.New(h, []byte(secret))
+		if len(signature) != mac.Size() {
+			continue
+		}
+		mac.Write(body)
+		return hmac.Equal(signature, mac.Sum(nil))
+	}
+	return false
+}
+
+// NonceTracker rejects nonces not greater than the last accepted nonce of an API key
+type NonceTracker struct {
+	mu   sync.Mutex
+	last map[string]int64
+}
+
+// NewNonceTracker creates an empty NonceTracker
+func NewNonceTracker() *NonceTracker {
+	return &NonceTracker{last: map[string]int64{}}
+}
+
+// Check accepts nonce for apiKey if it is greater than the last accepted one
+func (t *NonceTracker) Check(apiKey string, nonce int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if last, ok := t.last[apiKey]; ok && nonce <= last {
+		return fmt.Errorf("nonce %d is not greater than %d, %w", nonce, last, ErrInvalidNonce)
+	}
+	t.last[apiKey] = nonce
+	return nil
+}
+
+type verifiedRequestKey struct{}
+
+// VerifiedRequestFromContext returns the VerifiedRequest attached by VerifyHandler, nil if none
+func VerifiedRequestFromContext(ctx context.Context) *VerifiedRequest {
+	verified, _ := ctx.Value(verifiedRequestKey{}).(*VerifiedRequest)
+	return verified
+}
+
+// VerifyHandler verifies signed POST requests with VerifyRequest and rejects nonces not
+// greater than the last one of the API key, before passing them to next with the
+// VerifiedRequest in the context. Other requests, e.g. GET requests of public endpoints,
+// are passed unverified. Failed requests are answered with 401 and a JSON body like the
+// exchange's, which the client reports as an APIError matching ErrAuth. The body only
+// names the kind of failure, never the API key or the error of secretLookup.
+func VerifyHandler(next http.Handler, secretLookup SecretLookup) http.Handler {
+	nonces := NewNonceTracker()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+		verified, err := VerifyRequest(r, secretLookup)
+		if err == nil {
+			err = nonces.Check(verified.APIKey, verified.Nonce)
+		}
+		if err != nil {
+			body, _ := json.Marshal(map[string]interface{}{
+				"success": false,
+				"message": verifyFailureMessage(err),
+				"result":  []interface{}{},
+			})
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write(body)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), verifiedRequestKey{}, verified)))
+	})
+}
+
+// verifyFailureMessage returns the message sent to the caller of a failed verification,
+// without details of err which may reveal the API key or errors of the secret lookup
+func verifyFailureMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrUnknownAPIKey):
+		return "invalid api key"
+	case errors.Is(err, ErrPayloadMismatch):
+		return "invalid payload"
+	case errors.Is(err, ErrInvalidSignature):
+		return "invalid signature"
+	case errors.Is(err, ErrInvalidNonce):
+		return "invalid nonce"
+	case errors.Is(err, ErrRequestPathMismatch):
+		return "invalid request path"
+	case errors.Is(err, ErrRequestTooLarge):
+		return "request too large"
+	}
+	return "invalid request"
+}
@@ -0,0 +1,152 @@
+package p2pb2b
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func lookupSecret(apiKey string) (string, error) {
+	if apiKey != "key" {
+		return "", fmt.Errorf("not found")
+	}
+	return "secret", nil
+}
+
+func newVerifyingServer(verified *[]*VerifiedRequest) *httptest.Server {
+	return httptest.NewServer(VerifyHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v := VerifiedRequestFromContext(r.Context()); v != nil {
+			*verified = append(*verified, v)
+			body, _ := ioutil.ReadAll(r.Body)
+			if !bytes.Equal(v.Body, body) {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+		}
+		w.Write([]byte(`{"success":true,"message":"","result":{}}`))
+	}), lookupSecret))
+}
+
+func TestVerifyHandler(t *testing.T) {
+	var verified []*VerifiedRequest
+	ts := newVerifyingServer(&verified)
+	defer ts.Close()
+
+	// clients sharing an API key must share their nonces
+	nonces := NewTimeNonceSource()
+	for _, algorithm := range []SignatureAlgorithm{HMACSHA256, HMACSHA512} {
+		client, err := newClientWithURL(ts.URL, "key", "secret", WithSignatureAlgorithm(algorithm), WithNonceSource(nonces))
+		assert.Nil(t, err)
+		_, err = client.PostBalances(&AccountBalancesRequest{})
+		assert.Nil(t, err, algorithm.String())
+	}
+	if assert.Len(t, verified, 2) {
+		assert.Equal(t, "key", verified[0].APIKey)
+		assert.Equal(t, "/account/balances", verified[0].Path)
+		assert.True(t, verified[1].Nonce > 0)
+	}
+
+	// public requests are not verified
+	client, err := newClientWithURL(ts.URL, "", "")
+	assert.Nil(t, err)
+	_, err = client.GetTickers()
+	assert.Nil(t, err)
+
+	client, err = newClientWithURL(ts.URL, "key", "wrong")
+	assert.Nil(t, err)
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	assert.True(t, IsAuthError(err))
+	assert.Contains(t, err.Error(), "invalid signature")
+
+	// the response does not echo the API key or the error of the secret lookup
+	client, err = newClientWithURL(ts.URL, "unknown", "secret")
+	assert.Nil(t, err)
+	_, err = client.PostBalances(&AccountBalancesRequest{})
+	var apiErr *APIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.True(t, IsAuthError(err))
+		assert.Equal(t, "invalid api key", apiErr.Message)
+		assert.NotContains(t, string(apiErr.Body), "unknown")
+		assert.NotContains(t, string(apiErr.Body), "not found")
+	}
+}
+
+func TestVerifyHandlerRejectsReplayedNonce(t *testing.T) {
+	var verified []*VerifiedRequest
+	ts := newVerifyingServer(&verified)
+	defer ts.Close()
+
+	client, err := newClientWithURL(ts.URL, "key", "secret")
+	assert.Nil(t, err)
+	request := &AccountBalancesRequest{Request: Request{Nonce: "1574197772000"}}
+	_, err = client.PostBalances(request)
+	assert.Nil(t, err)
+	_, err = client.PostBalances(request)
+	if assert.True(t, IsAuthError(err)) {
+		assert.Contains(t, err.Error(), "nonce")
+	}
+	assert.Len(t, verified, 1)
+}
+
+func signedTestRequest(t *testing.T, path string, body string) *http.Request {
+	r := httptest.NewRequest("POST", path, bytes.NewReader([]byte(body)))
+	signer, err := NewHMACSigner(HMACSHA256, "secret")
+	assert.Nil(t, err)
+	headers, err := signer.Sign([]byte(body))
+	assert.Nil(t, err)
+	r.Header.Set(HeaderXTxcAPIKey, "key")
+	r.Header.Set(HeaderXTxcPayloard, base64.StdEncoding.EncodeToString([]byte(body)))
+	r.Header.Set(HeaderXTxcSignature, headers[HeaderXTxcSignature])
+	return r
+}
+
+func TestVerifyRequest(t *testing.T) {
+	body := `{"request":"/api/v1/order/new","nonce":"1574197772000","market":"ETH_BTC"}`
+
+	verified, err := VerifyRequest(signedTestRequest(t, "/api/v1/order/new", body), lookupSecret)
+	assert.Nil(t, err)
+	assert.Equal(t, &VerifiedRequest{APIKey: "key", Path: "/api/v1/order/new", Nonce: 1574197772000, Body: []byte(body)}, verified)
+
+	r := signedTestRequest(t, "/api/v1/order/new", body)
+	r.Body = ioutil.NopCloser(bytes.NewReader([]byte(`{"request":"/api/v1/order/new","nonce":"1574197772000","market":"LTC_BTC"}`)))
+	_, err = VerifyRequest(r, lookupSecret)
+	assert.True(t, errors.Is(err, ErrPayloadMismatch))
+
+	r = signedTestRequest(t, "/api/v1/order/new", body)
+	r.Header.Set(HeaderXTxcSignature, "00"+r.Header.Get(HeaderXTxcSignature)[2:])
+	_, err = VerifyRequest(r, lookupSecret)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+
+	_, err = VerifyRequest(signedTestRequest(t, "/api/v1/order/cancel", body), lookupSecret)
+	assert.True(t, errors.Is(err, ErrRequestPathMismatch))
+
+	_, err = VerifyRequest(signedTestRequest(t, "/api/v1/order/new", `{"request":"/api/v1/order/new","nonce":"abc"}`), lookupSecret)
+	assert.True(t, errors.Is(err, ErrInvalidNonce))
+
+	r = signedTestRequest(t, "/api/v1/order/new", body)
+	r.Header.Del(HeaderXTxcAPIKey)
+	_, err = VerifyRequest(r, lookupSecret)
+	assert.True(t, errors.Is(err, ErrUnknownAPIKey))
+
+	r = signedTestRequest(t, "/api/v1/order/new", body)
+	r.Body = ioutil.NopCloser(bytes.NewReader(make([]byte, maxRequestBodySize+1)))
+	_, err = VerifyRequest(r, lookupSecret)
+	assert.True(t, errors.Is(err, ErrRequestTooLarge))
+	assert.False(t, errors.Is(err, ErrResponseTooLarge))
+}
+
+func TestNonceTracker(t *testing.T) {
+	tracker := NewNonceTracker()
+	assert.Nil(t, tracker.Check("key", 2))
+	assert.True(t, errors.Is(tracker.Check("key", 2), ErrInvalidNonce))
+	assert.True(t, errors.Is(tracker.Check("key", 1), ErrInvalidNonce))
+	assert.Nil(t, tracker.Check("other", 1))
+	assert.Nil(t, tracker.Check("key", 3))
+}